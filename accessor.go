@@ -0,0 +1,145 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrIndexOutOfRange   = errors.New("index out of range")
+	ErrInvalidIndex      = errors.New("invalid index")
+	ErrUndefinedProperty = errors.New("undefined property")
+)
+
+// access reads an element or property of v, one dimension at a time, so
+// m[2, 3] is the third element of the second row. arrays of indices, such as
+// the range in m[1:2, 3], select a subset that keeps every dimension.
+//
+// unlike mathjs, fewer indices than dimensions do not select a subset:
+// [[1, 2], [3, 4]][1] is the row [1, 2] where mathjs gives [[1, 2]]. this
+// keeps m[1][2] the same as m[1, 2] and works for nested arrays that are not
+// matrices. write [[1, 2], [3, 4]][1, 1:2] for the subset
+func access(v Value, dims []Value) (Value, error) {
+	if _, isArray := v.([]Value); isArray && slices.ContainsFunc(dims, isIndexList) {
+		return subset(v, dims)
+	}
+
+	for _, key := range dims {
+		var err error
+		if v, err = accessOne(v, key); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func isIndexList(key Value) bool {
+	_, ok := key.([]Value)
+	return ok
+}
+
+// subset is access with a list of indices per dimension. a single index
+// selects a dimension of size one, as in mathjs m[2, 1:3] is [[4, 5, 6]]
+func subset(v Value, dims []Value) (Value, error) {
+	if len(dims) == 0 {
+		return v, nil
+	}
+
+	keys, ok := dims[0].([]Value)
+	if !ok {
+		keys = []Value{dims[0]}
+	}
+
+	out := make([]Value, 0, len(keys))
+	for _, key := range keys {
+		item, err := accessOne(v, key)
+		if err != nil {
+			return nil, err
+		}
+		if item, err = subset(item, dims[1:]); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func accessOne(v, key Value) (Value, error) {
+	switch x := v.(type) {
+	case []Value:
+		i, err := position(key, len(x))
+		if err != nil {
+			return nil, err
+		}
+		return x[i], nil
+	case string:
+		runes := []rune(x)
+		if keys, ok := key.([]Value); ok {
+			// "abcd"[2:3] is the substring "bc"
+			var sb strings.Builder
+			for _, k := range keys {
+				i, err := position(k, len(runes))
+				if err != nil {
+					return nil, err
+				}
+				sb.WriteRune(runes[i])
+			}
+			return sb.String(), nil
+		}
+		i, err := position(key, len(runes))
+		if err != nil {
+			return nil, err
+		}
+		return string(runes[i]), nil
+	case map[string]Value:
+		return property(key, func(name string) (Value, bool) {
+			v, ok := x[name]
+			return v, ok
+		})
+	case Scope:
+		return property(key, x.Get)
+	}
+
+	// Go structs expose the same fields as a StructScope over them
+	if s, err := NewStructScope(v); err == nil {
+		return property(key, s.Get)
+	}
+
+	return nil, fmt.Errorf("%w: cannot index %s", ErrInvalidIndex, typeOf(v))
+}
+
+func property(key Value, get func(name string) (Value, bool)) (Value, error) {
+	name, ok := key.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: property names are strings, got %s", ErrInvalidIndex, typeOf(key))
+	}
+
+	v, ok := get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUndefinedProperty, name)
+	}
+	return v, nil
+}
+
+// position converts the 1-based index key into a position in a sequence of
+// n items
+func position(key Value, n int) (int, error) {
+	switch key.(type) {
+	case float64, *big.Int, *big.Float, *big.Rat:
+	default:
+		return 0, fmt.Errorf("%w: indices are numbers, got %s", ErrInvalidIndex, typeOf(key))
+	}
+
+	x, _ := toNumber(key)
+	i, ok := floatToInteger(x)
+	if !ok {
+		return 0, fmt.Errorf("%w: index %s is not an integer", ErrInvalidIndex, Format(key))
+	}
+	if i < 1 || i > int64(n) {
+		return 0, fmt.Errorf("%w: index %d is not between 1 and %d", ErrIndexOutOfRange, i, n)
+	}
+	return int(i - 1), nil
+}
@@ -0,0 +1,220 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+var ErrDimensionMismatch = errors.New("dimension mismatch")
+
+// newArray checks that nested arrays form a matrix, that is every row has the
+// same size, the way mathjs does when it creates one
+func newArray(items []Value) ([]Value, error) {
+	if _, err := arraySize(items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// arraySize is the size of each dimension of a, e.g. [2, 3] for a matrix
+// with two rows of three
+func arraySize(a []Value) ([]int, error) {
+	if len(a) == 0 {
+		return []int{0}, nil
+	}
+
+	// the first item decides whether a is a vector or has rows
+	first, nested := a[0].([]Value)
+	var inner []int
+	if nested {
+		var err error
+		if inner, err = arraySize(first); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := 1; i < len(a); i++ {
+		row, isArray := a[i].([]Value)
+		switch {
+		case isArray != nested:
+			return nil, fmt.Errorf("%w: item %d is %s but item 0 is %s", ErrDimensionMismatch, i, typeOf(a[i]), typeOf(a[0]))
+		case !nested:
+			continue
+		}
+
+		size, err := arraySize(row)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Equal(size, inner) {
+			return nil, fmt.Errorf("%w: %v != %v at index %d", ErrDimensionMismatch, size, inner, i)
+		}
+	}
+
+	return append([]int{len(a)}, inner...), nil
+}
+
+// maxRangeLength bounds the number of values a range produces, so 1:1e12
+// fails instead of exhausting memory
+const maxRangeLength = 1 << 20
+
+// newRange evaluates start:step:end, the values from start to end inclusive.
+// each value is start + i * step rather than a running sum, so 0:0.1:1 ends
+// at 1 and not at 0.9999999999999999. a range that moves away from end is
+// empty, as in mathjs
+func newRange(start, end, step Value) ([]Value, error) {
+	for _, v := range []Value{start, end, step} {
+		if k := kindOf(v); k == notANumber || k == kindComplex {
+			return nil, fmt.Errorf("%w: range expects real numbers, got %s", ErrInvalidOperand, typeOf(v))
+		}
+	}
+
+	if cmp, ok := compareNumbers(step, 0.0); !ok || cmp == 0 {
+		return nil, fmt.Errorf("%w: range step must not be zero", ErrArgumentValue)
+	}
+
+	// the number of steps is computed once rather than by comparing each
+	// value to end, which at large magnitudes may never be past it. it is
+	// floored nearly, so 0:0.1:0.3 keeps 0.3 like in mathjs
+	sub, div := binaryOperators[OperatorFnSubtract], binaryOperators[OperatorFnDivide]
+	diff, err := sub(end, start)
+	if err != nil {
+		return nil, err
+	}
+	q, err := div(diff, step)
+	if err != nil {
+		return nil, err
+	}
+	steps, _ := toNumber(q)
+	steps = floorNearly(steps)
+	switch {
+	case steps >= maxRangeLength:
+		return nil, fmt.Errorf("%w: range has more than %d values", ErrArgumentValue, maxRangeLength)
+	case !(steps >= 0):
+		// moving away from end, or NaN
+		return []Value{}, nil
+	}
+
+	add, mul := binaryOperators[OperatorFnAdd], binaryOperators[OperatorFnMultiply]
+	out := make([]Value, 0, int(steps)+1)
+	for i := range int(steps) + 1 {
+		// a bigint step keeps the values bigints
+		var index Value = float64(i)
+		if _, ok := step.(*big.Int); ok {
+			index = big.NewInt(int64(i))
+		}
+		offset, err := mul(index, step)
+		if err != nil {
+			return nil, err
+		}
+		x, err := add(start, offset)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, x)
+	}
+	return out, nil
+}
+
+// flatten lists the values of nested arrays in order
+func flatten(a []Value) []Value {
+	out := make([]Value, 0, len(a))
+	for _, item := range a {
+		if row, ok := item.([]Value); ok {
+			out = append(out, flatten(row)...)
+		} else {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// concatArrays joins arrays along their last dimension like concat in
+// mathjs, so concat([1, 2; 3, 4], [5; 6]) is [1, 2, 5; 3, 4, 6]. the other
+// dimensions must match
+func concatArrays(arrays [][]Value) ([]Value, error) {
+	var first []int
+	for _, a := range arrays {
+		size, err := arraySize(a)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case first == nil:
+			first = size
+		case len(size) != len(first):
+			return nil, fmt.Errorf("%w: cannot concat arrays of %d and %d dimensions", ErrDimensionMismatch, len(first), len(size))
+		case !slices.Equal(size[:len(size)-1], first[:len(first)-1]):
+			return nil, fmt.Errorf("%w: %v != %v in function concat", ErrDimensionMismatch, size, first)
+		}
+	}
+	return concatLast(arrays, len(first)), nil
+}
+
+// concatLast joins arrays of the same size but for the last of their dims
+// dimensions
+func concatLast(arrays [][]Value, dims int) []Value {
+	if dims == 1 {
+		out := []Value{}
+		for _, a := range arrays {
+			out = append(out, a...)
+		}
+		return out
+	}
+
+	out := make([]Value, len(arrays[0]))
+	rows := make([][]Value, len(arrays))
+	for i := range out {
+		for j, a := range arrays {
+			rows[j] = a[i].([]Value)
+		}
+		out[i] = concatLast(rows, dims-1)
+	}
+	return out
+}
+
+// registerArrays adds the functions that reduce arrays, which also take
+// their values as separate arguments, e.g. sum(1:3) or sum(1, 2, 3)
+func registerArrays(r *Registry) {
+	r.MustRegister("concat", func(a []Value, rest ...[]Value) ([]Value, error) {
+		return concatArrays(append([][]Value{a}, rest...))
+	})
+
+	sum := func(values []Value) (Value, error) {
+		if len(values) == 0 {
+			return 0.0, nil
+		}
+		return reduceValues(values, binaryOperators[OperatorFnAdd])
+	}
+	r.MustRegister("sum", func(a []Value) (Value, error) { return sum(flatten(a)) })
+	r.MustRegister("sum", func(x Value, rest ...Value) (Value, error) { return sum(append([]Value{x}, rest...)) })
+
+	for name, test := range map[string]OperatorFnName{"max": OperatorFnGt, "min": OperatorFnLt} {
+		better := binaryOperators[test]
+		r.MustRegister(name, func(a []Value) (Value, error) {
+			values := flatten(a)
+			if len(values) == 0 {
+				return nil, fmt.Errorf("%w: cannot calculate %s of an empty array", ErrArgumentValue, name)
+			}
+			return reduceValues(values, func(acc, v Value) (Value, error) {
+				if wins, err := better(v, acc); err != nil || wins != true {
+					return acc, err
+				}
+				return v, nil
+			})
+		})
+	}
+}
+
+func reduceValues(values []Value, fn binaryOperator) (Value, error) {
+	acc := values[0]
+	for _, v := range values[1:] {
+		var err error
+		if acc, err = fn(acc, v); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
@@ -0,0 +1,665 @@
+package mathematigo
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// DefaultPrecision is the number of significant decimal digits of
+// BigNumbers when WithPrecision is not given, the same default as mathjs
+const DefaultPrecision = 64
+
+// guardBits is the extra working precision used by the series below, so
+// their rounding errors stay out of the final digits
+const guardBits = 32
+
+// maxBigFactorial bounds n! for BigNumbers, larger values take too long to
+// compute to be useful
+const maxBigFactorial = 100_000
+
+var defaultBigPrec = bigPrecisionBits(DefaultPrecision)
+
+// bigPrecisionBits is the mantissa size in bits that holds digits decimal
+// digits
+func bigPrecisionBits(digits int) uint {
+	return uint(math.Ceil(float64(digits) * math.Log2(10)))
+}
+
+// bigDecimalDigits is the number of decimal digits a mantissa of prec bits
+// represents exactly
+func bigDecimalDigits(prec uint) int {
+	return int(float64(prec) * math.Log10(2))
+}
+
+// bigPrecOf is the largest precision of the BigNumbers in values, or the
+// default when there are none
+func bigPrecOf(values ...Value) uint {
+	var prec uint
+	for _, v := range values {
+		if b, ok := v.(*big.Float); ok {
+			prec = max(prec, b.Prec())
+		}
+	}
+	if prec == 0 {
+		return defaultBigPrec
+	}
+	return prec
+}
+
+// parseBig reads decimal text. the base is fixed to 10 so literals like
+// 0b101 are not silently accepted
+func parseBig(text string, prec uint) (*big.Float, error) {
+	f, _, err := big.ParseFloat(text, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid BigNumber %q", ErrInvalidSyntax, text)
+	}
+	return f, nil
+}
+
+// toBig converts a number to a BigNumber. floats go through their shortest
+// decimal form, so 0.1 becomes exactly 0.1 rather than the binary value
+// closest to it. ok is false for NaN, which a BigNumber cannot hold
+func toBig(v Value, prec uint) (*big.Float, bool) {
+	switch x := v.(type) {
+	case *big.Float:
+		return x, true
+	case *big.Rat:
+		return new(big.Float).SetPrec(prec).SetRat(x), true
+	case *big.Int:
+		return new(big.Float).SetPrec(prec).SetInt(x), true
+	}
+
+	x, ok := toNumber(v)
+	if !ok || math.IsNaN(x) {
+		return nil, false
+	}
+	if math.IsInf(x, 0) {
+		return new(big.Float).SetPrec(prec).SetInf(x < 0), true
+	}
+
+	b, err := parseBig(strconv.FormatFloat(x, 'g', -1, 64), prec)
+	return b, err == nil
+}
+
+func bigNaNErr(fn string) error {
+	return fmt.Errorf("%w: BigNumber %s is undefined", ErrNotANumber, fn)
+}
+
+// bigCatch turns the big.ErrNaN panics of math/big, e.g. for Inf - Inf,
+// into an error
+func bigCatch(fn func() *big.Float) (out Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			nan, ok := r.(big.ErrNaN)
+			if !ok {
+				panic(r)
+			}
+			err = fmt.Errorf("%w: %s", ErrNotANumber, nan.Error())
+		}
+	}()
+
+	return fn(), nil
+}
+
+func bigArith(x, y *big.Float, op func(z, x, y *big.Float) *big.Float) (Value, error) {
+	return bigCatch(func() *big.Float {
+		return op(new(big.Float).SetPrec(max(x.Prec(), y.Prec())), x, y)
+	})
+}
+
+// bigCompare orders x and y, treating values that differ only in the last
+// few bits of their precision as equal, like nearlyEqual does for floats
+func bigCompare(x, y *big.Float) int {
+	cmp := x.Cmp(y)
+	if cmp == 0 || x.IsInf() || y.IsInf() {
+		return cmp
+	}
+
+	prec := max(x.Prec(), y.Prec())
+	diff := new(big.Float).SetPrec(prec).Sub(x, y)
+	diff.Abs(diff)
+
+	scale := new(big.Float).Abs(x)
+	if abs := new(big.Float).Abs(y); abs.Cmp(scale) > 0 {
+		scale = abs
+	}
+	tolerance := new(big.Float).SetMantExp(scale, 8-int(prec))
+
+	if diff.Cmp(tolerance) <= 0 {
+		return 0
+	}
+	return cmp
+}
+
+func bigInt64(x int64, prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec).SetInt64(x)
+}
+
+func bigTrunc(x *big.Float) *big.Float {
+	if x.IsInf() || x.IsInt() {
+		return new(big.Float).Copy(x)
+	}
+	i, _ := x.Int(nil)
+	return new(big.Float).SetPrec(x.Prec()).SetInt(i)
+}
+
+func bigFloor(x *big.Float) *big.Float {
+	out := bigTrunc(x)
+	if x.Sign() < 0 && out.Cmp(x) != 0 {
+		out.Sub(out, bigInt64(1, x.Prec()))
+	}
+	return out
+}
+
+func bigCeil(x *big.Float) *big.Float {
+	out := bigFloor(new(big.Float).Neg(x))
+	return out.Neg(out)
+}
+
+// bigMod is floorMod for BigNumbers
+func bigMod(x, y *big.Float) (Value, error) {
+	if y.Sign() == 0 {
+		return new(big.Float).Copy(x), nil
+	}
+
+	return bigCatch(func() *big.Float {
+		prec := max(x.Prec(), y.Prec())
+		q := new(big.Float).SetPrec(prec+guardBits).Quo(x, y)
+		q = bigFloor(q)
+		q.Mul(q, y)
+		return new(big.Float).SetPrec(prec).Sub(x, q)
+	})
+}
+
+// bigPow is exact, up to rounding, for integer exponents and uses
+// exp(y * log(x)) otherwise
+func bigPow(x, y *big.Float) (Value, error) {
+	prec := max(x.Prec(), y.Prec())
+
+	if y.IsInt() {
+		// -n overflows for MinInt64, which takes the exp and log path below
+		if n, acc := y.Int64(); acc == big.Exact && n != math.MinInt64 {
+			return bigCatch(func() *big.Float { return bigPowInt(x, n, prec) })
+		}
+	}
+
+	switch {
+	case x.Sign() < 0:
+		// the result is complex
+		fx, _ := x.Float64()
+		fy, _ := y.Float64()
+		return floatPow(fx, fy), nil
+	case x.Sign() == 0 || x.IsInf():
+		// 0^y is 0 for positive y and Inf otherwise, Inf^y is the opposite
+		if (y.Sign() > 0) == x.IsInf() {
+			return new(big.Float).SetPrec(prec).SetInf(false), nil
+		}
+		return new(big.Float).SetPrec(prec), nil
+	}
+
+	wprec := prec + guardBits + 64 + uint(max(0, y.MantExp(nil)))
+	l, err := bigLog(x, wprec)
+	if err != nil {
+		return nil, err
+	}
+	l.Mul(l, y)
+	return new(big.Float).SetPrec(prec).Set(bigExp(l, wprec)), nil
+}
+
+func bigPowInt(x *big.Float, n int64, prec uint) *big.Float {
+	m := n
+	if m < 0 {
+		m = -m
+	}
+
+	wprec := prec + guardBits + 64
+	base := new(big.Float).SetPrec(wprec).Set(x)
+	out := bigInt64(1, wprec)
+	for ; m > 0; m >>= 1 {
+		if m&1 == 1 {
+			out.Mul(out, base)
+		}
+		base.Mul(base, base)
+	}
+
+	if n < 0 {
+		out.Quo(bigInt64(1, wprec), out)
+	}
+	return new(big.Float).SetPrec(prec).Set(out)
+}
+
+// bigExp computes e^x by halving x until the Taylor series converges
+// quickly and squaring the result back up
+func bigExp(x *big.Float, prec uint) *big.Float {
+	switch {
+	case x.Sign() == 0:
+		return bigInt64(1, prec)
+	case x.IsInf() || x.MantExp(nil) > 32:
+		// beyond the exponent range of big.Float
+		if x.Sign() > 0 {
+			return new(big.Float).SetPrec(prec).SetInf(false)
+		}
+		return new(big.Float).SetPrec(prec)
+	}
+
+	halvings := max(0, x.MantExp(nil)+8)
+	wprec := prec + guardBits + uint(halvings)
+
+	r := new(big.Float).SetPrec(wprec).SetMantExp(x, -halvings)
+	sum := bigInt64(1, wprec)
+	term := bigInt64(1, wprec)
+	for i := int64(1); ; i++ {
+		term.Mul(term, r)
+		term.Quo(term, bigInt64(i, wprec))
+		if bigNegligible(term, sum, wprec) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+
+	for range halvings {
+		sum.Mul(sum, sum)
+	}
+	return new(big.Float).SetPrec(prec).Set(sum)
+}
+
+// bigNegligible reports whether adding term to sum no longer changes sum at
+// prec bits
+func bigNegligible(term, sum *big.Float, prec uint) bool {
+	return term.Sign() == 0 || (sum.Sign() != 0 && term.MantExp(nil) < sum.MantExp(nil)-int(prec))
+}
+
+// bigLog is the natural logarithm. x is split into m * 2^e, so that
+// ln x = ln m + e ln 2 with ln m from a fast converging atanh series
+func bigLog(x *big.Float, prec uint) (*big.Float, error) {
+	switch {
+	case x.Sign() < 0:
+		return nil, bigNaNErr("logarithm of a negative number")
+	case x.Sign() == 0:
+		return new(big.Float).SetPrec(prec).SetInf(true), nil
+	case x.IsInf():
+		return new(big.Float).SetPrec(prec).SetInf(false), nil
+	}
+
+	wprec := prec + guardBits
+	m := new(big.Float).SetPrec(wprec)
+	e := x.MantExp(m)
+	// move m from [0.5, 1) to [0.7, 1.4) so the series converges faster
+	if m.Cmp(big.NewFloat(math.Sqrt2/2)) < 0 {
+		m.SetMantExp(m, 1)
+		e--
+	}
+
+	one := bigInt64(1, wprec)
+	z := new(big.Float).SetPrec(wprec).Sub(m, one)
+	z.Quo(z, new(big.Float).SetPrec(wprec).Add(m, one))
+
+	out := bigAtanh(z, wprec)
+	out.SetMantExp(out, 1)
+
+	if e != 0 {
+		ln2 := bigLn2(wprec)
+		out.Add(out, ln2.Mul(ln2, bigInt64(int64(e), wprec)))
+	}
+	return new(big.Float).SetPrec(prec).Set(out), nil
+}
+
+// bigLn2 is ln 2 = 2 atanh(1/3)
+func bigLn2(prec uint) *big.Float {
+	third := new(big.Float).SetPrec(prec).Quo(bigInt64(1, prec), bigInt64(3, prec))
+	out := bigAtanh(third, prec)
+	return out.SetMantExp(out, 1)
+}
+
+// bigAtanh sums z + z^3/3 + z^5/5 + ..., for |z| well below 1
+func bigAtanh(z *big.Float, prec uint) *big.Float {
+	sum := new(big.Float).SetPrec(prec).Set(z)
+	pow := new(big.Float).SetPrec(prec).Set(z)
+	z2 := new(big.Float).SetPrec(prec).Mul(z, z)
+	term := new(big.Float).SetPrec(prec)
+
+	for k := int64(3); z.Sign() != 0; k += 2 {
+		pow.Mul(pow, z2)
+		term.Quo(pow, bigInt64(k, prec))
+		if bigNegligible(term, sum, prec) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	return sum
+}
+
+// bigPi uses Machin's formula pi = 16 atan(1/5) - 4 atan(1/239)
+func bigPi(prec uint) *big.Float {
+	wprec := prec + guardBits
+	a := bigAtanInverse(5, wprec)
+	a.Mul(a, bigInt64(16, wprec))
+	b := bigAtanInverse(239, wprec)
+	b.Mul(b, bigInt64(4, wprec))
+	return new(big.Float).SetPrec(prec).Sub(a, b)
+}
+
+// bigAtanInverse is atan(1/n) for an integer n > 1
+func bigAtanInverse(n int64, prec uint) *big.Float {
+	x := new(big.Float).SetPrec(prec).Quo(bigInt64(1, prec), bigInt64(n, prec))
+	sum := new(big.Float).SetPrec(prec).Set(x)
+	pow := new(big.Float).SetPrec(prec).Set(x)
+	x2 := new(big.Float).SetPrec(prec).Mul(x, x)
+	term := new(big.Float).SetPrec(prec)
+
+	for k := int64(3); ; k += 2 {
+		pow.Mul(pow, x2)
+		term.Quo(pow, bigInt64(k, prec))
+		if bigNegligible(term, sum, prec) {
+			return sum
+		}
+		if k%4 == 3 {
+			sum.Sub(sum, term)
+		} else {
+			sum.Add(sum, term)
+		}
+	}
+}
+
+// bigSinCos computes sin x or cos x from their Taylor series, after
+// reducing x into [-pi, pi]
+func bigSinCos(x *big.Float, cos bool) (*big.Float, error) {
+	if x.IsInf() {
+		return nil, bigNaNErr("sine or cosine of Inf")
+	}
+
+	prec := x.Prec()
+	wprec := prec + guardBits + uint(max(0, x.MantExp(nil)))
+
+	twoPi := bigPi(wprec)
+	twoPi.SetMantExp(twoPi, 1)
+	k := new(big.Float).SetPrec(wprec).Quo(x, twoPi)
+	k = bigFloor(k.Add(k, big.NewFloat(0.5)))
+	r := new(big.Float).SetPrec(wprec).Sub(x, k.Mul(k, twoPi))
+
+	r2 := new(big.Float).SetPrec(wprec).Mul(r, r)
+	term := new(big.Float).SetPrec(wprec).Set(r)
+	i := int64(1)
+	if cos {
+		term = bigInt64(1, wprec)
+		i = 0
+	}
+	sum := new(big.Float).SetPrec(wprec).Set(term)
+
+	for ; ; i += 2 {
+		term.Mul(term, r2)
+		term.Quo(term, bigInt64((i+1)*(i+2), wprec))
+		term.Neg(term)
+		if bigNegligible(term, sum, wprec) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	return new(big.Float).SetPrec(prec).Set(sum), nil
+}
+
+func bigTan(x *big.Float) (*big.Float, error) {
+	s, err := bigSinCos(x, false)
+	if err != nil {
+		return nil, err
+	}
+	c, err := bigSinCos(x, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.Quo(s, c), nil
+}
+
+func bigSqrt(x *big.Float) *big.Float {
+	if x.IsInf() {
+		return new(big.Float).Copy(x)
+	}
+	return new(big.Float).SetPrec(x.Prec()).Sqrt(x)
+}
+
+// bigSqrtReal is complex for negative numbers, like sqrtReal
+func bigSqrtReal(x *big.Float) Value {
+	if x.Sign() < 0 {
+		f, _ := bigSqrt(new(big.Float).Neg(x)).Float64()
+		return complex(0, f)
+	}
+	return bigSqrt(x)
+}
+
+// bigLogReal is complex for negative numbers, like logReal
+func bigLogReal(x *big.Float) (Value, error) {
+	if x.Sign() < 0 {
+		f, _ := x.Float64()
+		return logReal(f), nil
+	}
+	return bigLog(x, x.Prec())
+}
+
+func bigFactorial(x *big.Float) (Value, error) {
+	switch {
+	case x.Sign() < 0:
+		return nil, fmt.Errorf("%w: value must be non-negative in function %s", ErrInvalidOperand, OperatorFnFactorial)
+	case x.IsInf():
+		return new(big.Float).Copy(x), nil
+	case !x.IsInt():
+		return nil, fmt.Errorf("%w: value must be an integer in function %s for BigNumbers", ErrInvalidOperand, OperatorFnFactorial)
+	}
+
+	n, _ := x.Int64()
+	if n > maxBigFactorial {
+		return nil, fmt.Errorf("%w: value must be at most %d in function %s for BigNumbers", ErrInvalidOperand, maxBigFactorial, OperatorFnFactorial)
+	}
+
+	out := new(big.Int).MulRange(1, n)
+	return new(big.Float).SetPrec(x.Prec()).SetInt(out), nil
+}
+
+func bigBitwise(fn OperatorFnName, x, y *big.Float, op func(z, x, y *big.Int) *big.Int) (Value, error) {
+	if !x.IsInt() || !y.IsInt() {
+		return nil, integersExpectedErr(fn)
+	}
+
+	i, _ := x.Int(nil)
+	j, _ := y.Int(nil)
+	out := op(new(big.Int), i, j)
+	return new(big.Float).SetPrec(max(x.Prec(), y.Prec())).SetInt(out), nil
+}
+
+func bigShift(fn OperatorFnName, x, y *big.Float, op func(z, x *big.Int, n uint) *big.Int) (Value, error) {
+	if !x.IsInt() || !y.IsInt() {
+		return nil, integersExpectedErr(fn)
+	}
+
+	i, _ := x.Int(nil)
+	j, _ := y.Int(nil)
+	n, err := shiftCount(fn, j)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Float).SetPrec(x.Prec()).SetInt(op(new(big.Int), i, n)), nil
+}
+
+// bigRoundDecimal rounds half away from zero at n decimals. like
+// roundDecimal it works on the decimal digits x represents, not on its
+// binary value
+func bigRoundDecimal(x *big.Float, n int) *big.Float {
+	if x.IsInf() {
+		return new(big.Float).Copy(x)
+	}
+
+	r, ok := new(big.Rat).SetString(x.Text('g', bigDecimalDigits(x.Prec())))
+	if !ok {
+		return new(big.Float).Copy(x)
+	}
+	return new(big.Float).SetPrec(x.Prec()).SetRat(roundRat(r, n))
+}
+
+// bigScaled applies fn at n decimals
+func bigScaled(name string, x *big.Float, n int, fn func(*big.Float) *big.Float) (*big.Float, error) {
+	if err := checkDecimals(name, n); err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return fn(x), nil
+	}
+
+	prec := x.Prec()
+	pow := new(big.Float).SetPrec(prec).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+	out := fn(new(big.Float).SetPrec(prec).Mul(x, pow))
+	return out.Quo(out, pow), nil
+}
+
+// bigFloorNearly and bigCeilNearly snap values that are equal to an integer
+// within the precision to it, see floorNearly
+func bigFloorNearly(x *big.Float) *big.Float {
+	if r := bigRoundDecimal(x, 0); bigCompare(x, r) == 0 {
+		return r
+	}
+	return bigFloor(x)
+}
+
+func bigCeilNearly(x *big.Float) *big.Float {
+	if r := bigRoundDecimal(x, 0); bigCompare(x, r) == 0 {
+		return r
+	}
+	return bigCeil(x)
+}
+
+func bigGCD(a, b *big.Float) (*big.Float, error) {
+	if !a.IsInt() || !b.IsInt() {
+		return nil, fmt.Errorf("%w: integers expected in function gcd", ErrArgumentType)
+	}
+
+	i, _ := a.Int(nil)
+	j, _ := b.Int(nil)
+	out := new(big.Int).GCD(nil, nil, i.Abs(i), j.Abs(j))
+	return new(big.Float).SetPrec(max(a.Prec(), b.Prec())).SetInt(out), nil
+}
+
+func bigLCM(a, b *big.Float) (*big.Float, error) {
+	if !a.IsInt() || !b.IsInt() {
+		return nil, fmt.Errorf("%w: integers expected in function lcm", ErrArgumentType)
+	}
+
+	prec := max(a.Prec(), b.Prec())
+	if a.Sign() == 0 || b.Sign() == 0 {
+		return new(big.Float).SetPrec(prec), nil
+	}
+
+	i, _ := a.Int(nil)
+	j, _ := b.Int(nil)
+	i.Abs(i)
+	j.Abs(j)
+	g := new(big.Int).GCD(nil, nil, i, j)
+	out := i.Mul(i.Quo(i, g), j)
+	return new(big.Float).SetPrec(prec).SetInt(out), nil
+}
+
+func reduceBig(acc *big.Float, rest []*big.Float, fn func(a, b *big.Float) (*big.Float, error)) (*big.Float, error) {
+	for _, x := range rest {
+		var err error
+		if acc, err = fn(acc, x); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+func bigMax(a, b *big.Float) (*big.Float, error) {
+	if b.Cmp(a) > 0 {
+		return b, nil
+	}
+	return a, nil
+}
+
+func bigMin(a, b *big.Float) (*big.Float, error) {
+	if b.Cmp(a) < 0 {
+		return b, nil
+	}
+	return a, nil
+}
+
+// registerBigNumber adds the BigNumber overloads of the standard functions.
+// it runs after the float64 overloads are registered, so booleans and null,
+// which convert to both equally well, keep calling the float64 versions
+func registerBigNumber(r *Registry) {
+	r.MustRegister("abs", func(x *big.Float) *big.Float { return new(big.Float).Abs(x) })
+	r.MustRegister("sign", func(x *big.Float) *big.Float { return bigInt64(int64(x.Sign()), x.Prec()) })
+	r.MustRegister("fix", bigTrunc)
+	r.MustRegister("sqrt", bigSqrtReal)
+	r.MustRegister("exp", func(x *big.Float) *big.Float { return bigExp(x, x.Prec()) })
+
+	r.MustRegister("log", bigLogReal)
+	r.MustRegister("log", func(x, base *big.Float) (Value, error) {
+		if x.Sign() < 0 || base.Sign() < 0 {
+			fx, _ := x.Float64()
+			fb, _ := base.Float64()
+			return logBase(fx, fb), nil
+		}
+		return bigLogBase(x, base)
+	})
+	r.MustRegister("log10", func(x *big.Float) (*big.Float, error) { return bigLogBase(x, bigInt64(10, x.Prec())) })
+	r.MustRegister("log2", func(x *big.Float) (*big.Float, error) { return bigLogBase(x, bigInt64(2, x.Prec())) })
+
+	r.MustRegister("sin", func(x *big.Float) (*big.Float, error) { return bigSinCos(x, false) })
+	r.MustRegister("cos", func(x *big.Float) (*big.Float, error) { return bigSinCos(x, true) })
+	r.MustRegister("tan", bigTan)
+
+	r.MustRegister("round", func(x *big.Float) *big.Float { return bigRoundDecimal(x, 0) })
+	r.MustRegister("round", func(x *big.Float, n int) (*big.Float, error) {
+		if err := checkDecimals("round", n); err != nil {
+			return nil, err
+		}
+		return bigRoundDecimal(x, n), nil
+	})
+	r.MustRegister("floor", bigFloorNearly)
+	r.MustRegister("floor", func(x *big.Float, n int) (*big.Float, error) { return bigScaled("floor", x, n, bigFloorNearly) })
+	r.MustRegister("ceil", bigCeilNearly)
+	r.MustRegister("ceil", func(x *big.Float, n int) (*big.Float, error) { return bigScaled("ceil", x, n, bigCeilNearly) })
+
+	r.MustRegister("max", func(x *big.Float, rest ...*big.Float) (*big.Float, error) { return reduceBig(x, rest, bigMax) })
+	r.MustRegister("min", func(x *big.Float, rest ...*big.Float) (*big.Float, error) { return reduceBig(x, rest, bigMin) })
+	r.MustRegister("hypot", func(x *big.Float, rest ...*big.Float) (*big.Float, error) {
+		prec := bigPrecOf(x)
+		sum := new(big.Float).SetPrec(prec).Mul(x, x)
+		for _, y := range rest {
+			sum.Add(sum, new(big.Float).SetPrec(prec).Mul(y, y))
+		}
+		return bigSqrt(sum), nil
+	})
+
+	r.MustRegister("gcd", func(a, b *big.Float, rest ...*big.Float) (*big.Float, error) {
+		g, err := bigGCD(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return reduceBig(g, rest, bigGCD)
+	})
+	r.MustRegister("lcm", func(a, b *big.Float, rest ...*big.Float) (*big.Float, error) {
+		l, err := bigLCM(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return reduceBig(l, rest, bigLCM)
+	})
+}
+
+func bigLogBase(x, base *big.Float) (*big.Float, error) {
+	prec := max(x.Prec(), base.Prec())
+	lx, err := bigLog(x, prec+guardBits)
+	if err != nil {
+		return nil, err
+	}
+	lb, err := bigLog(base, prec+guardBits)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := bigCatch(func() *big.Float { return lx.Quo(lx, lb) })
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Float).SetPrec(prec).Set(out.(*big.Float)), nil
+}
@@ -0,0 +1,185 @@
+package mathematigo
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evalBig(t *testing.T, expr string, scope Scope, opts ...EvalOption) *big.Float {
+	t.Helper()
+
+	node, err := Parse(expr, ParseNumbers(NumberBigNumber))
+	require.NoError(t, err)
+
+	v, err := NewEvaluator(opts...).Evaluate(node, scope)
+	require.NoError(t, err)
+
+	require.IsType(t, &big.Float{}, v, expr)
+	return v.(*big.Float)
+}
+
+func bigText(b *big.Float) string {
+	return b.Text('g', bigDecimalDigits(b.Prec()))
+}
+
+func TestParseBigNumbers(t *testing.T) {
+	node, err := Parse("0.1 + 123456789012345678901234567890", ParseNumbers(NumberBigNumber))
+	require.NoError(t, err)
+
+	expected := &OperatorNode{
+		Args: []MathNode{NewBigNumberNode("0.1"), NewBigNumberNode("123456789012345678901234567890")},
+		Op:   "+",
+		Fn:   OperatorFnAdd,
+	}
+	assert.True(t, expected.Equal(node), node.String())
+	assert.Equal(t, "0.1 + 123456789012345678901234567890", node.String())
+
+	// the default is unchanged
+	node, err = Parse("0.1")
+	require.NoError(t, err)
+	assert.IsType(t, NewFloatNode(0), node)
+}
+
+func TestBigNumberArithmetic(t *testing.T) {
+	cases := map[string]string{
+		"0.1 + 0.2":                          "0.3",
+		"123456789012345678901234567890 + 1": "123456789012345678901234567891",
+		"1 / 3":                              "0.3333333333333333333333333333333333333333333333333333333333333333",
+		"2 ^ 100":                            "1267650600228229401496703205376",
+		"2 ^ -2":                             "0.25",
+		"-7 % 3":                             "2",
+		"7 % 0":                              "7",
+		"25!":                                "15511210043330985984000000",
+		"6 | 3":                              "7",
+		"6 & 3":                              "2",
+		"19.99 * 3":                          "59.97",
+		"100000000000000000000.5 - 100000000000000000000": "0.5",
+		// -2^63 has no positive counterpart in an int64
+		"2 ^ (0-9223372036854775808)": "0",
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, bigText(evalBig(t, expr, nil)), expr)
+	}
+}
+
+func TestBigNumberComparisons(t *testing.T) {
+	cases := map[string]bool{
+		"0.1 + 0.2 == 0.3": true,
+		"1 / 3 * 3 == 1":   true,
+		"100000000000000000001 > 100000000000000000000": true,
+		"0.3 < 0.1 + 0.2": false,
+		"x == 0.5":        true,
+	}
+
+	for expr, expected := range cases {
+		node, err := Parse(expr, ParseNumbers(NumberBigNumber))
+		require.NoError(t, err)
+
+		v, err := Evaluate(node, MapScope{"x": 0.5})
+		require.NoError(t, err)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestBigNumberPrecision(t *testing.T) {
+	v := evalBig(t, "1 / 3", nil, WithPrecision(20))
+	assert.Equal(t, "0.33333333333333333333", bigText(v))
+
+	v = evalBig(t, "1 / 7", nil, WithPrecision(100))
+	assert.Equal(t, 100, bigDecimalDigits(v.Prec()))
+}
+
+func TestEvaluateWithBigNumbers(t *testing.T) {
+	// float nodes are converted by the evaluator
+	node, err := Parse("0.1 + 0.2 * x")
+	require.NoError(t, err)
+
+	e := NewEvaluator(WithNumbers(NumberBigNumber))
+	v, err := e.Evaluate(node, MapScope{"x": 1.0})
+	require.NoError(t, err)
+	assert.Equal(t, "0.3", bigText(v.(*big.Float)))
+
+	p, err := e.Compile(node)
+	require.NoError(t, err)
+	v, err = p.Eval(MapScope{"x": 2.0})
+	require.NoError(t, err)
+	assert.Equal(t, "0.5", bigText(v.(*big.Float)))
+
+	_, err = CompileBytecode(NewBigNumberNode("1"))
+	require.ErrorIs(t, err, ErrUnsupportedNode)
+}
+
+func TestBigNumberFunctions(t *testing.T) {
+	cases := map[string]string{
+		"sqrt(2)":         "1.414213562373095048801688724209698078569671875376948073176679738",
+		"exp(1)":          "2.718281828459045235360287471352662497757247093699959574966967628",
+		"log(10)":         "2.302585092994045684017991454684364207601101488628772976033327901",
+		"log(8, 2)":       "3",
+		"log10(1000)":     "3",
+		"sin(0)":          "0",
+		"cos(0)":          "1",
+		"2 ^ 0.5":         "1.414213562373095048801688724209698078569671875376948073176679738",
+		"abs(-2.5)":       "2.5",
+		"round(2.5)":      "3",
+		"round(1.005, 2)": "1.01",
+		"floor(-2.5)":     "-3",
+		"ceil(2.123, 1)":  "2.2",
+		"max(1, 3.5, 2)":  "3.5",
+		"min(1, 3.5, 2)":  "1",
+		"hypot(3, 4)":     "5",
+		"gcd(12, 18)":     "6",
+		"lcm(4, 6)":       "12",
+		"sign(-4)":        "-1",
+		"fix(-2.7)":       "-2",
+		"add(0.1, 0.2)":   "0.3",
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, bigText(evalBig(t, expr, nil)), expr)
+	}
+
+	// pi from Machin's formula, checked against its known digits
+	assert.Equal(t, "3.141592653589793238462643383279502884197169399375105820974944592", bigText(bigPi(defaultBigPrec)))
+	v := evalBig(t, "sin(x)", MapScope{"x": bigPi(defaultBigPrec)})
+	assert.True(t, v.MantExp(nil) < -200, bigText(v))
+
+	// results that are complex leave BigNumbers
+	node, err := Parse("sqrt(-4) + log(-1)", ParseNumbers(NumberBigNumber))
+	require.NoError(t, err)
+	z, err := Evaluate(node, nil)
+	require.NoError(t, err)
+	assert.Equal(t, complex(0, 2+math.Pi), z)
+
+	// functions without a BigNumber overload fall back to float64
+	node, err = Parse("cbrt(27)", ParseNumbers(NumberBigNumber))
+	require.NoError(t, err)
+	f, err := Evaluate(node, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 3.0, f, 1e-12)
+}
+
+func TestBigNumberErrors(t *testing.T) {
+	cases := map[string]error{
+		"1.5!":             ErrInvalidOperand,
+		"1.5 | 1":          ErrInvalidOperand,
+		"gcd(1.5, 2)":      ErrArgumentType,
+		"round(1.234, 16)": ErrArgumentValue,
+	}
+
+	for expr, expected := range cases {
+		node, err := Parse(expr, ParseNumbers(NumberBigNumber))
+		require.NoError(t, err)
+
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, expected, expr)
+	}
+
+	// Inf - Inf has no BigNumber value
+	_, err := binaryOperators[OperatorFnSubtract](new(big.Float).SetInf(false), new(big.Float).SetInf(false))
+	require.ErrorIs(t, err, ErrNotANumber)
+}
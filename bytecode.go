@@ -0,0 +1,630 @@
+package mathematigo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrTooManyOperands  = errors.New("too many constants, variables or functions")
+	ErrInvalidBytecode  = errors.New("invalid bytecode")
+	ErrBytecodeConstant = errors.New("constant cannot be stored in bytecode")
+)
+
+type opcode byte
+
+const (
+	// bcConst idx:u16 pushes a constant from the pool
+	bcConst opcode = iota
+	// bcLoad slot:u16 pushes a variable
+	bcLoad
+	// bcStore slot:u16 assigns the top of the stack to a variable, leaving it
+	// on the stack
+	bcStore
+	// bcPop discards the top of the stack, used between statements
+	bcPop
+	// bcCall fn:u16 argc:u8 calls a function with argc arguments from the stack
+	bcCall
+	// bcJump target:u16 continues at target
+	bcJump
+	// bcJumpIfFalse target:u16 pops a condition and continues at target when
+	// it is false, used by conditionals
+	bcJumpIfFalse
+	// bcJumpIfTrue target:u16 is bcJumpIfFalse for true conditions
+	bcJumpIfTrue
+
+	// unary operators, each takes one value off the stack
+	bcUnaryMinus
+	bcFactorial
+	bcNot
+	bcBitNot
+
+	// binary operators, each takes two values off the stack
+	bcAdd
+	bcSubtract
+	bcMultiply
+	bcDivide
+	bcMod
+	bcPow
+	bcBitOr
+	bcBitAnd
+	bcEqual
+	bcUnequal
+	bcLarger
+	bcLargerEq
+	bcSmaller
+	bcSmallerEq
+	bcTo
+	bcXor
+	bcBitXor
+	bcLeftShift
+	bcRightArithShift
+	bcRightLogShift
+
+	opcodeCount
+)
+
+// operatorOpcodes maps the operators the VM understands to their opcodes
+var operatorOpcodes = map[OperatorFnName]opcode{
+	OperatorFnUnaryMinus: bcUnaryMinus,
+	OperatorFnFactorial:  bcFactorial,
+	OperatorFnAdd:        bcAdd,
+	OperatorFnSubtract:   bcSubtract,
+	OperatorFnMultiply:   bcMultiply,
+	OperatorFnDivide:     bcDivide,
+	OperatorFnMod:        bcMod,
+	OperatorFnPower:      bcPow,
+	OperatorFnBitOr:      bcBitOr,
+	OperatorFnBitAnd:     bcBitAnd,
+	OperatorFnEqual:      bcEqual,
+	OperatorFnUnequal:    bcUnequal,
+	OperatorFnGt:         bcLarger,
+	OperatorFnGteq:       bcLargerEq,
+	OperatorFnLt:         bcSmaller,
+	OperatorFnLteq:       bcSmallerEq,
+	OperatorFnTo:         bcTo,
+	OperatorFnNot:        bcNot,
+	OperatorFnXor:        bcXor,
+
+	OperatorFnBitNot:          bcBitNot,
+	OperatorFnBitXor:          bcBitXor,
+	OperatorFnLeftShift:       bcLeftShift,
+	OperatorFnRightArithShift: bcRightArithShift,
+	OperatorFnRightLogShift:   bcRightLogShift,
+}
+
+// opcodeOperators is the reverse of operatorOpcodes, indexed by opcode
+var opcodeOperators = func() [opcodeCount]OperatorFnName {
+	var out [opcodeCount]OperatorFnName
+	for fn, op := range operatorOpcodes {
+		out[op] = fn
+	}
+	return out
+}()
+
+func (op opcode) isUnary() bool  { return op >= bcUnaryMinus && op < bcAdd }
+func (op opcode) isBinary() bool { return op >= bcAdd && op < opcodeCount }
+
+// operandWidth is the number of bytes following the opcode
+func (op opcode) operandWidth() int {
+	switch op {
+	case bcConst, bcLoad, bcStore, bcJump, bcJumpIfFalse, bcJumpIfTrue:
+		return 2
+	case bcCall:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Bytecode is a MathNode lowered to instructions for the stack VM. constants
+// live in a pool and variables are addressed by slot, see Slots
+type Bytecode struct {
+	code      []byte
+	constants []Value
+	slots     []string
+	functions []string
+	maxStack  int
+}
+
+// Slots lists the variable names in slot order
+func (b *Bytecode) Slots() []string {
+	return append([]string(nil), b.slots...)
+}
+
+type bytecodeCompiler struct {
+	bc        *Bytecode
+	constants map[Value]int
+	slots     map[string]int
+	functions map[string]int
+	depth     int
+}
+
+// CompileBytecode lowers node to Bytecode. only OperatorNode, FunctionNode,
+// SymbolNode, AssignmentNode, ConditionalNode, ParenthesisNode, BlockNode and
+// literal nodes are supported
+func CompileBytecode(node MathNode) (*Bytecode, error) {
+	c := &bytecodeCompiler{
+		bc:        &Bytecode{},
+		constants: map[Value]int{},
+		slots:     map[string]int{},
+		functions: map[string]int{},
+	}
+
+	if err := c.compile(node); err != nil {
+		return nil, err
+	}
+
+	return c.bc, nil
+}
+
+func (c *bytecodeCompiler) emit(op opcode, operands ...byte) {
+	c.bc.code = append(c.bc.code, byte(op))
+	c.bc.code = append(c.bc.code, operands...)
+}
+
+// jump emits op with a placeholder target and returns its position for patch
+func (c *bytecodeCompiler) jump(op opcode) int {
+	c.emit(op, 0, 0)
+	return len(c.bc.code) - 2
+}
+
+// patch points the jump at pos to the next instruction
+func (c *bytecodeCompiler) patch(pos int) error {
+	target := len(c.bc.code)
+	if target > math.MaxUint16 {
+		return ErrTooManyOperands
+	}
+	c.bc.code[pos] = byte(target >> 8)
+	c.bc.code[pos+1] = byte(target)
+	return nil
+}
+
+func (c *bytecodeCompiler) push(n int) {
+	c.depth += n
+	if c.depth > c.bc.maxStack {
+		c.bc.maxStack = c.depth
+	}
+}
+
+// index finds name in table or adds it to list
+func index[K comparable](table map[K]int, list *[]K, key K) (byte, byte, error) {
+	idx, ok := table[key]
+	if !ok {
+		idx = len(*list)
+		if idx > math.MaxUint16 {
+			return 0, 0, ErrTooManyOperands
+		}
+		table[key] = idx
+		*list = append(*list, key)
+	}
+	return byte(idx >> 8), byte(idx), nil
+}
+
+func (c *bytecodeCompiler) constant(v Value) error {
+	// floats are keyed by their bits so 0 and -0 stay distinct
+	key := v
+	if f, ok := v.(float64); ok {
+		key = math.Float64bits(f)
+	}
+
+	idx, ok := c.constants[key]
+	if !ok {
+		idx = len(c.bc.constants)
+		if idx > math.MaxUint16 {
+			return ErrTooManyOperands
+		}
+		c.constants[key] = idx
+		c.bc.constants = append(c.bc.constants, v)
+	}
+
+	c.emit(bcConst, byte(idx>>8), byte(idx))
+	c.push(1)
+	return nil
+}
+
+func (c *bytecodeCompiler) compile(node MathNode) error {
+	switch n := node.(type) {
+	case *FloatNode:
+		return c.constant(float64(*n))
+	case *IntNode:
+		return c.constant(float64(*n))
+	case *BigIntNode:
+		// bytecode constants are float64, like the VM's arithmetic
+		f, _ := toNumber(n.value)
+		return c.constant(f)
+	case *BooleanNode:
+		return c.constant(bool(*n))
+	case *ConstantNode:
+		return c.constant(string(*n))
+	case *NullNode:
+		return c.constant(nil)
+	case *SymbolNode:
+		hi, lo, err := index(c.slots, &c.bc.slots, n.Name)
+		if err != nil {
+			return err
+		}
+		c.emit(bcLoad, hi, lo)
+		c.push(1)
+		return nil
+	case *AssignmentNode:
+		if err := c.compile(n.Value); err != nil {
+			return err
+		}
+		hi, lo, err := index(c.slots, &c.bc.slots, n.Object.Name)
+		if err != nil {
+			return err
+		}
+		c.emit(bcStore, hi, lo)
+		return nil
+	case *ConditionalNode:
+		if err := c.compile(n.Condition); err != nil {
+			return err
+		}
+		toFalse := c.jump(bcJumpIfFalse)
+		c.push(-1)
+		if err := c.compile(n.TrueExpr); err != nil {
+			return err
+		}
+		toEnd := c.jump(bcJump)
+		// only one branch runs, the false branch reuses the stack slot
+		c.push(-1)
+		if err := c.patch(toFalse); err != nil {
+			return err
+		}
+		if err := c.compile(n.FalseExpr); err != nil {
+			return err
+		}
+		return c.patch(toEnd)
+	case *ParenthesisNode:
+		return c.compile(n.Content)
+	case *OperatorNode:
+		if decides, ok := shortCircuits[n.Fn]; ok && len(n.Args) == 2 {
+			return c.shortCircuit(n, decides)
+		}
+		op, ok := operatorOpcodes[n.Fn]
+		if !ok || (len(n.Args) == 1) != op.isUnary() || len(n.Args) > 2 {
+			return fmt.Errorf("%w: %s with %d argument(s)", ErrUnsupportedOperator, n.Fn, len(n.Args))
+		}
+		for _, arg := range n.Args {
+			if err := c.compile(arg); err != nil {
+				return err
+			}
+		}
+		c.emit(op)
+		c.push(1 - len(n.Args))
+		return nil
+	case *FunctionNode:
+		if len(n.Args) > math.MaxUint8 {
+			return ErrTooManyOperands
+		}
+		hi, lo, err := index(c.functions, &c.bc.functions, n.Fn.Name)
+		if err != nil {
+			return err
+		}
+		for _, arg := range n.Args {
+			if err := c.compile(arg); err != nil {
+				return err
+			}
+		}
+		c.emit(bcCall, hi, lo, byte(len(n.Args)))
+		c.push(1 - len(n.Args))
+		return nil
+	case *BlockNode:
+		// the value of the last visible statement stays on the stack
+		last := -1
+		for i := range n.Blocks {
+			if n.Visible(i) {
+				last = i
+			}
+		}
+		for i, block := range n.Blocks {
+			if err := c.compile(block); err != nil {
+				return err
+			}
+			if i != last {
+				c.emit(bcPop)
+				c.push(-1)
+			}
+		}
+		if last < 0 {
+			return c.constant(nil)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedNode, node)
+	}
+}
+
+// shortCircuit lowers and and or to jumps, so the right operand only runs
+// when the left one does not decide the result
+func (c *bytecodeCompiler) shortCircuit(n *OperatorNode, decides bool) error {
+	skip := bcJumpIfFalse
+	if decides {
+		skip = bcJumpIfTrue
+	}
+
+	jumps := make([]int, 0, len(n.Args))
+	for _, arg := range n.Args {
+		if err := c.compile(arg); err != nil {
+			return err
+		}
+		jumps = append(jumps, c.jump(skip))
+		c.push(-1)
+	}
+
+	if err := c.constant(!decides); err != nil {
+		return err
+	}
+	toEnd := c.jump(bcJump)
+	c.push(-1)
+
+	for _, pos := range jumps {
+		if err := c.patch(pos); err != nil {
+			return err
+		}
+	}
+	if err := c.constant(decides); err != nil {
+		return err
+	}
+	return c.patch(toEnd)
+}
+
+// bytecodeMagic starts every serialized Bytecode, the last byte is the version
+var bytecodeMagic = []byte{'M', 'G', 'B', 'C', 5}
+
+const (
+	constNull byte = iota
+	constFloat
+	constString
+	constTrue
+	constFalse
+)
+
+// MarshalBinary serializes the bytecode so it can be cached and loaded
+// later with UnmarshalBinary
+func (b *Bytecode) MarshalBinary() ([]byte, error) {
+	out := append([]byte(nil), bytecodeMagic...)
+
+	out = binary.AppendUvarint(out, uint64(b.maxStack))
+	out = binary.AppendUvarint(out, uint64(len(b.code)))
+	out = append(out, b.code...)
+
+	out = binary.AppendUvarint(out, uint64(len(b.constants)))
+	for _, c := range b.constants {
+		switch v := c.(type) {
+		case nil:
+			out = append(out, constNull)
+		case float64:
+			out = append(out, constFloat)
+			out = binary.LittleEndian.AppendUint64(out, math.Float64bits(v))
+		case string:
+			out = append(out, constString)
+			out = appendString(out, v)
+		case bool:
+			if v {
+				out = append(out, constTrue)
+			} else {
+				out = append(out, constFalse)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrBytecodeConstant, typeOf(c))
+		}
+	}
+
+	for _, names := range [][]string{b.slots, b.functions} {
+		out = binary.AppendUvarint(out, uint64(len(names)))
+		for _, name := range names {
+			out = appendString(out, name)
+		}
+	}
+
+	return out, nil
+}
+
+func appendString(out []byte, s string) []byte {
+	out = binary.AppendUvarint(out, uint64(len(s)))
+	return append(out, s...)
+}
+
+// bytecodeReader consumes serialized bytecode, remembering the first error
+type bytecodeReader struct {
+	data []byte
+	err  error
+}
+
+func (r *bytecodeReader) fail() {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: truncated data", ErrInvalidBytecode)
+	}
+}
+
+func (r *bytecodeReader) uvarint() int {
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 || v > math.MaxInt32 {
+		r.fail()
+		return 0
+	}
+	r.data = r.data[n:]
+	return int(v)
+}
+
+func (r *bytecodeReader) bytes(n int) []byte {
+	if r.err != nil || n > len(r.data) {
+		r.fail()
+		return nil
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *bytecodeReader) string() string {
+	return string(r.bytes(r.uvarint()))
+}
+
+func (r *bytecodeReader) strings() []string {
+	n := r.uvarint()
+	var out []string
+	for i := 0; i < n && r.err == nil; i++ {
+		out = append(out, r.string())
+	}
+	return out
+}
+
+// UnmarshalBinary loads bytecode produced by MarshalBinary. the instructions
+// are verified so a corrupt cache entry is reported instead of crashing the VM
+func (b *Bytecode) UnmarshalBinary(data []byte) error {
+	r := &bytecodeReader{data: data}
+
+	magic := r.bytes(len(bytecodeMagic))
+	if r.err == nil && string(magic) != string(bytecodeMagic) {
+		return fmt.Errorf("%w: unknown header", ErrInvalidBytecode)
+	}
+
+	out := Bytecode{}
+	out.maxStack = r.uvarint()
+	out.code = append([]byte(nil), r.bytes(r.uvarint())...)
+
+	n := r.uvarint()
+	for i := 0; i < n && r.err == nil; i++ {
+		switch tag := r.bytes(1); {
+		case tag == nil:
+		case tag[0] == constNull:
+			out.constants = append(out.constants, nil)
+		case tag[0] == constFloat:
+			bits := r.bytes(8)
+			if bits != nil {
+				out.constants = append(out.constants, math.Float64frombits(binary.LittleEndian.Uint64(bits)))
+			}
+		case tag[0] == constString:
+			out.constants = append(out.constants, r.string())
+		case tag[0] == constTrue:
+			out.constants = append(out.constants, true)
+		case tag[0] == constFalse:
+			out.constants = append(out.constants, false)
+		default:
+			return fmt.Errorf("%w: unknown constant tag %d", ErrInvalidBytecode, tag[0])
+		}
+	}
+
+	out.slots = r.strings()
+	out.functions = r.strings()
+
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != 0 {
+		return fmt.Errorf("%w: trailing data", ErrInvalidBytecode)
+	}
+	if err := out.verify(); err != nil {
+		return err
+	}
+
+	*b = out
+	return nil
+}
+
+// verify checks that every instruction is known, its operands are in range
+// and the stack never exceeds maxStack or underflows. jumps only go forward
+// to an instruction, so a program always ends
+func (b *Bytecode) verify() error {
+	// every push needs at least one instruction
+	if b.maxStack > len(b.code) {
+		return fmt.Errorf("%w: stack size %d is too large", ErrInvalidBytecode, b.maxStack)
+	}
+
+	// targets holds the stack depth each pending jump expects at its target
+	targets := map[int]int{}
+	arrive := func(ip, depth int) error {
+		if expected, ok := targets[ip]; ok && expected != depth {
+			return fmt.Errorf("%w: stack mismatch at jump target %d", ErrInvalidBytecode, ip)
+		}
+		delete(targets, ip)
+		return nil
+	}
+
+	depth := 0
+	for ip := 0; ip < len(b.code); {
+		if err := arrive(ip, depth); err != nil {
+			return err
+		}
+
+		op := opcode(b.code[ip])
+		if op >= opcodeCount || ip+1+op.operandWidth() > len(b.code) {
+			return fmt.Errorf("%w: bad instruction at %d", ErrInvalidBytecode, ip)
+		}
+		operands := b.code[ip+1 : ip+1+op.operandWidth()]
+
+		pops, pushes := 0, 1
+		switch {
+		case op == bcConst:
+			if readUint16(operands) >= len(b.constants) {
+				return fmt.Errorf("%w: constant out of range at %d", ErrInvalidBytecode, ip)
+			}
+		case op == bcLoad || op == bcStore:
+			if readUint16(operands) >= len(b.slots) {
+				return fmt.Errorf("%w: slot out of range at %d", ErrInvalidBytecode, ip)
+			}
+			if op == bcStore {
+				pops = 1
+			}
+		case op == bcCall:
+			if readUint16(operands) >= len(b.functions) {
+				return fmt.Errorf("%w: function out of range at %d", ErrInvalidBytecode, ip)
+			}
+			pops = int(operands[2])
+		case op == bcJump || op == bcJumpIfFalse || op == bcJumpIfTrue:
+			target := readUint16(operands)
+			if target <= ip || target > len(b.code) {
+				return fmt.Errorf("%w: jump out of range at %d", ErrInvalidBytecode, ip)
+			}
+			// the value left by the branch that jumps is counted again by
+			// the branch that falls through, so the jump takes it along
+			pops, pushes = 1, 0
+			expected := depth - 1
+			if op == bcJump {
+				expected = depth
+			}
+			if prev, ok := targets[target]; ok && prev != expected {
+				return fmt.Errorf("%w: stack mismatch at jump target %d", ErrInvalidBytecode, target)
+			}
+			targets[target] = expected
+		case op == bcPop:
+			pops, pushes = 1, 0
+		case op.isUnary():
+			pops = 1
+		case op.isBinary():
+			pops = 2
+		}
+
+		if depth < pops {
+			return fmt.Errorf("%w: stack underflow at %d", ErrInvalidBytecode, ip)
+		}
+		depth += pushes - pops
+		if depth > b.maxStack {
+			return fmt.Errorf("%w: stack overflow at %d", ErrInvalidBytecode, ip)
+		}
+
+		ip += 1 + op.operandWidth()
+	}
+
+	if err := arrive(len(b.code), depth); err != nil {
+		return err
+	}
+	if len(targets) != 0 {
+		return fmt.Errorf("%w: jump into an instruction", ErrInvalidBytecode)
+	}
+
+	if depth != 1 {
+		return fmt.Errorf("%w: program leaves %d values on the stack", ErrInvalidBytecode, depth)
+	}
+
+	return nil
+}
+
+func readUint16(b []byte) int {
+	return int(b[0])<<8 | int(b[1])
+}
@@ -0,0 +1,341 @@
+package mathematigo
+
+import "fmt"
+
+// evalFunc is one compiled node
+type evalFunc func(scope Scope) (Value, error)
+
+// Program is a MathNode compiled into nested closures. operators and
+// registered functions are resolved once by Compile, so Eval does no type
+// switching over nodes. a Program is safe for concurrent use
+type Program struct {
+	root evalFunc
+}
+
+// Compile compiles node using the default Evaluator
+func Compile(node MathNode) (*Program, error) {
+	return NewEvaluator().Compile(node)
+}
+
+// Compile compiles node into a Program bound to the evaluator's functions
+func (e *Evaluator) Compile(node MathNode) (*Program, error) {
+	root, err := e.compile(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{root: root}, nil
+}
+
+// Eval runs the program. like Evaluate, scope may be nil
+func (p *Program) Eval(scope Scope) (Value, error) {
+	if scope == nil {
+		scope = MapScope{}
+	}
+
+	return p.root(scope)
+}
+
+func constant(v Value) evalFunc {
+	return func(Scope) (Value, error) { return v, nil }
+}
+
+func (e *Evaluator) compile(node MathNode) (evalFunc, error) {
+	switch n := node.(type) {
+	case *FloatNode, *IntNode, *BigIntNode, *BigNumberNode, *FractionNode, *BooleanNode, *ConstantNode, *NullNode:
+		// literals are evaluated once, the closure returns the boxed value
+		v, err := e.eval(n, nil)
+		if err != nil {
+			return nil, err
+		}
+		return constant(v), nil
+	case *SymbolNode:
+		name := n.Name
+		fallback, isConstant := e.builtin(name)
+		return func(scope Scope) (Value, error) {
+			if v, ok := scope.Get(name); ok {
+				return v, nil
+			}
+			if isConstant {
+				return fallback, nil
+			}
+			return nil, fmt.Errorf("%w: %s", ErrUndefinedSymbol, name)
+		}, nil
+	case *ParenthesisNode:
+		return e.compile(n.Content)
+	case *OperatorNode:
+		return e.compileOperator(n)
+	case *FunctionNode:
+		return e.compileFunction(n)
+	case *AssignmentNode:
+		value, err := e.compile(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		name := n.Object.Name
+		return func(scope Scope) (Value, error) {
+			v, err := value(scope)
+			if err != nil {
+				return nil, err
+			}
+			if err := scope.Set(name, v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}, nil
+	case *ConditionalNode:
+		cond, err := e.compile(n.Condition)
+		if err != nil {
+			return nil, err
+		}
+		trueExpr, err := e.compile(n.TrueExpr)
+		if err != nil {
+			return nil, err
+		}
+		falseExpr, err := e.compile(n.FalseExpr)
+		if err != nil {
+			return nil, err
+		}
+		return func(scope Scope) (Value, error) {
+			v, err := cond(scope)
+			if err != nil {
+				return nil, err
+			}
+			ok, err := truthy(v)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return trueExpr(scope)
+			}
+			return falseExpr(scope)
+		}, nil
+	case *ArrayNode:
+		items, err := e.compileAll(n.Items)
+		if err != nil {
+			return nil, err
+		}
+		return func(scope Scope) (Value, error) {
+			values := make([]Value, len(items))
+			for i, item := range items {
+				v, err := item(scope)
+				if err != nil {
+					return nil, err
+				}
+				values[i] = v
+			}
+			return newArray(values)
+		}, nil
+	case *RangeNode:
+		parts, err := e.compileAll([]MathNode{n.Start, n.End})
+		if err != nil {
+			return nil, err
+		}
+		stepFn := constant(e.number(1))
+		if n.Step != nil {
+			if stepFn, err = e.compile(n.Step); err != nil {
+				return nil, err
+			}
+		}
+		return func(scope Scope) (Value, error) {
+			start, err := parts[0](scope)
+			if err != nil {
+				return nil, err
+			}
+			end, err := parts[1](scope)
+			if err != nil {
+				return nil, err
+			}
+			step, err := stepFn(scope)
+			if err != nil {
+				return nil, err
+			}
+			return newRange(start, end, step)
+		}, nil
+	case *ObjectNode:
+		keys := make([]string, 0, len(n.Properties))
+		values := make([]evalFunc, 0, len(n.Properties))
+		for _, prop := range n.Properties {
+			v, err := e.compile(prop.Value)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, prop.Key)
+			values = append(values, v)
+		}
+		return func(scope Scope) (Value, error) {
+			out := make(map[string]Value, len(keys))
+			for i, value := range values {
+				v, err := value(scope)
+				if err != nil {
+					return nil, err
+				}
+				out[keys[i]] = v
+			}
+			return out, nil
+		}, nil
+	case *AccessorNode:
+		obj, err := e.compile(n.Object)
+		if err != nil {
+			return nil, err
+		}
+		dims, err := e.compileAll(n.Index.Dimensions)
+		if err != nil {
+			return nil, err
+		}
+		return func(scope Scope) (Value, error) {
+			v, err := obj(scope)
+			if err != nil {
+				return nil, err
+			}
+			keys := make([]Value, len(dims))
+			for i, dim := range dims {
+				if keys[i], err = dim(scope); err != nil {
+					return nil, err
+				}
+			}
+			return access(v, keys)
+		}, nil
+	case *FunctionAssignmentNode:
+		body, err := e.compile(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return func(scope Scope) (Value, error) {
+			return e.defineFunction(n, body, scope)
+		}, nil
+	case *BlockNode:
+		blocks, err := e.compileAll(n.Blocks)
+		if err != nil {
+			return nil, err
+		}
+		visible := make([]bool, len(blocks))
+		for i := range visible {
+			visible[i] = n.Visible(i)
+		}
+		return func(scope Scope) (Value, error) {
+			var out Value
+			for i, block := range blocks {
+				v, err := block(scope)
+				if err != nil {
+					return nil, err
+				}
+				if visible[i] {
+					out = v
+				}
+			}
+			return out, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedNode, node)
+	}
+}
+
+func (e *Evaluator) compileOperator(n *OperatorNode) (evalFunc, error) {
+	args, err := e.compileAll(n.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(args) {
+	case 1:
+		if op, ok := unaryOperators[n.Fn]; ok {
+			arg := args[0]
+			return func(scope Scope) (Value, error) {
+				a, err := arg(scope)
+				if err != nil {
+					return nil, err
+				}
+				return op(a)
+			}, nil
+		}
+	case 2:
+		if decides, ok := shortCircuits[n.Fn]; ok {
+			left, right := args[0], args[1]
+			return func(scope Scope) (Value, error) {
+				a, err := left(scope)
+				if err != nil {
+					return nil, err
+				}
+				x, err := truthy(a)
+				if err != nil {
+					return nil, err
+				}
+				if x == decides {
+					return x, nil
+				}
+				b, err := right(scope)
+				if err != nil {
+					return nil, err
+				}
+				y, err := truthy(b)
+				if err != nil {
+					return nil, err
+				}
+				return y, nil
+			}, nil
+		}
+		if op, ok := binaryOperators[n.Fn]; ok {
+			left, right := args[0], args[1]
+			return func(scope Scope) (Value, error) {
+				a, err := left(scope)
+				if err != nil {
+					return nil, err
+				}
+				b, err := right(scope)
+				if err != nil {
+					return nil, err
+				}
+				return op(a, b)
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s with %d argument(s)", ErrUnsupportedOperator, n.Fn, len(args))
+}
+
+func (e *Evaluator) compileFunction(n *FunctionNode) (evalFunc, error) {
+	args, err := e.compileAll(n.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	name := n.Fn.Name
+	bound := e.function(name)
+
+	return func(scope Scope) (Value, error) {
+		fn := bound
+		// functions in the scope shadow registered ones, same as Evaluate
+		if v, ok := scope.Get(name); ok {
+			if scoped, ok := v.(Function); ok {
+				fn = scoped
+			}
+		}
+		if fn == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUndefinedFunction, name)
+		}
+
+		values := make([]Value, len(args))
+		for i, arg := range args {
+			v, err := arg(scope)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+
+		return fn(values...)
+	}, nil
+}
+
+func (e *Evaluator) compileAll(nodes []MathNode) ([]evalFunc, error) {
+	out := make([]evalFunc, 0, len(nodes))
+	for _, node := range nodes {
+		f, err := e.compile(node)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
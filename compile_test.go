@@ -0,0 +1,130 @@
+package mathematigo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileMatchesEvaluate(t *testing.T) {
+	exprs := []string{
+		"1 + 2 * 3",
+		"(a + b) ^ 2 % 7",
+		"-a! + 6 | 1",
+		"a > b == false",
+		`"gold" == tier`,
+		"a\nb\na * b",
+		"double(a) - b",
+		"c = a * b\nc + 1",
+		"a > b ? a : b - 1",
+		"a < b ? a : b - 1",
+		"c = a * b; c + 1",
+		"c = a; d = b;",
+		"a\nb;\n;",
+		"a > b and b > 0 or not a",
+		"a xor b",
+	}
+
+	double := Function(func(args ...Value) (Value, error) {
+		return args[0].(float64) * 2, nil
+	})
+	e := NewEvaluator(WithFunction("double", double))
+	scope := MapScope{"a": 3.0, "b": 4.0, "tier": "gold"}
+
+	for _, expr := range exprs {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+
+		expected, err := e.Evaluate(node, scope)
+		require.NoError(t, err, expr)
+
+		p, err := e.Compile(node)
+		require.NoError(t, err, expr)
+
+		actual, err := p.Eval(scope)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, actual, expr)
+	}
+}
+
+func TestCompiledProgramIsReusable(t *testing.T) {
+	node, err := Parse("price * qty")
+	require.NoError(t, err)
+
+	p, err := Compile(node)
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		v, err := p.Eval(MapScope{"price": 2.5, "qty": float64(i)})
+		require.NoError(t, err)
+		assert.Equal(t, 2.5*float64(i), v)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := Compile(NewOperatorNode("?", "unknown", NewFloatNode(1), NewFloatNode(2)))
+	require.ErrorIs(t, err, ErrUnsupportedOperator)
+
+	node, err := Parse("missing(1) + x")
+	require.NoError(t, err)
+
+	p, err := Compile(node)
+	require.NoError(t, err)
+
+	_, err = p.Eval(nil)
+	require.ErrorIs(t, err, ErrUndefinedFunction)
+
+	_, err = p.Eval(MapScope{"missing": Function(func(args ...Value) (Value, error) { return args[0], nil })})
+	require.ErrorIs(t, err, ErrUndefinedSymbol)
+}
+
+const benchmarkExpr = "(price * qty - discount) * (1 + tax) > limit == true | 0"
+
+func benchmarkScope() MapScope {
+	return MapScope{"price": 19.99, "qty": 3.0, "discount": 5.0, "tax": 0.2, "limit": 50.0}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	node, err := Parse(benchmarkExpr)
+	require.NoError(b, err)
+
+	e := NewEvaluator()
+	scope := benchmarkScope()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := e.Evaluate(node, scope); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgramEval(b *testing.B) {
+	node, err := Parse(benchmarkExpr)
+	require.NoError(b, err)
+
+	p, err := Compile(node)
+	require.NoError(b, err)
+
+	scope := benchmarkScope()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := p.Eval(scope); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCompileArrays(t *testing.T) {
+	node, err := Parse("[a, b; b, a]")
+	require.NoError(t, err)
+
+	p, err := Compile(node)
+	require.NoError(t, err)
+
+	v, err := p.Eval(MapScope{"a": 1.0, "b": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, `[[1, "x"], ["x", 1]]`, Format(v))
+}
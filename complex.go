@@ -0,0 +1,106 @@
+package mathematigo
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// maxExactComplexPow is the largest integer exponent complexPow computes by
+// repeated multiplication. (1 + i)^2 is then exactly 2i, where the polar
+// form would leave a rounding error in the real part
+const maxExactComplexPow = 64
+
+// toComplex converts any number to a complex128
+func toComplex(v Value) (complex128, bool) {
+	if z, ok := v.(complex128); ok {
+		return z, true
+	}
+	x, ok := toNumber(v)
+	return complex(x, 0), ok
+}
+
+func complexPow(x, y complex128) complex128 {
+	n := real(y)
+	if imag(y) != 0 || n != math.Trunc(n) || math.Abs(n) > maxExactComplexPow {
+		return cmplx.Pow(x, y)
+	}
+
+	out := complex(1, 0)
+	for m := int(math.Abs(n)); m > 0; m >>= 1 {
+		if m&1 == 1 {
+			out *= x
+		}
+		x *= x
+	}
+	if n < 0 {
+		return 1 / out
+	}
+	return out
+}
+
+// floatPow is math.Pow, except that fractional powers of negative numbers
+// are complex, e.g. (-4) ^ 0.5 is 2i
+func floatPow(x, y float64) Value {
+	if x < 0 && y != math.Trunc(y) && !math.IsInf(y, 0) {
+		return complexPow(complex(x, 0), complex(y, 0))
+	}
+	return math.Pow(x, y)
+}
+
+// complexReal is the real part of z for operators that are only defined on
+// the real numbers
+func complexReal(fn OperatorFnName, z complex128) (float64, error) {
+	if imag(z) != 0 {
+		return 0, fmt.Errorf("%w: %s is not defined for complex numbers", ErrInvalidOperand, fn)
+	}
+	return real(z), nil
+}
+
+func complexEqual(x, y complex128) bool {
+	return nearlyEqual(real(x), real(y)) && nearlyEqual(imag(x), imag(y))
+}
+
+// sqrtReal and logReal return complex results for negative numbers
+func sqrtReal(x float64) Value {
+	if x < 0 {
+		return complex(0, math.Sqrt(-x))
+	}
+	return math.Sqrt(x)
+}
+
+func logReal(x float64) Value {
+	if x < 0 {
+		return cmplx.Log(complex(x, 0))
+	}
+	return math.Log(x)
+}
+
+func logBase(x, base float64) Value {
+	if x < 0 || base < 0 {
+		return cmplx.Log(complex(x, 0)) / cmplx.Log(complex(base, 0))
+	}
+	return math.Log(x) / math.Log(base)
+}
+
+// registerComplex adds the complex overloads of the standard functions and
+// the functions that take complex numbers apart
+func registerComplex(r *Registry) {
+	r.MustRegister("re", func(x float64) float64 { return x })
+	r.MustRegister("re", func(z complex128) float64 { return real(z) })
+	r.MustRegister("im", func(x float64) float64 { return 0 })
+	r.MustRegister("im", func(z complex128) float64 { return imag(z) })
+	r.MustRegister("conj", func(x float64) float64 { return x })
+	r.MustRegister("conj", cmplx.Conj)
+	r.MustRegister("arg", func(x float64) float64 { return math.Atan2(0, x) })
+	r.MustRegister("arg", cmplx.Phase)
+
+	r.MustRegister("abs", cmplx.Abs)
+	r.MustRegister("sqrt", cmplx.Sqrt)
+	r.MustRegister("exp", cmplx.Exp)
+	r.MustRegister("log", cmplx.Log)
+	r.MustRegister("log", func(z, base complex128) complex128 { return cmplx.Log(z) / cmplx.Log(base) })
+	r.MustRegister("sin", cmplx.Sin)
+	r.MustRegister("cos", cmplx.Cos)
+	r.MustRegister("tan", cmplx.Tan)
+}
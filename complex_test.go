@@ -0,0 +1,96 @@
+package mathematigo
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComplexArithmetic(t *testing.T) {
+	cases := map[string]complex128{
+		"(2 + 3i) * (1 - i)": complex(5, 1),
+		"3i":                 complex(0, 3),
+		"i ^ 2":              -1,
+		"(1 + i) ^ 2":        complex(0, 2),
+		"(1 + i) ^ -1":       complex(0.5, -0.5),
+		"(4 + 2i) / (1 + i)": complex(3, -1),
+		"-(1 - i)":           complex(-1, 1),
+		"2 - i + 0.5":        complex(2.5, -1),
+		"(-4) ^ 0.5":         complex(0, 2),
+		"sqrt(-4)":           complex(0, 2),
+		"sqrt(2i)":           complex(1, 1),
+		"conj(1 + 2i)":       complex(1, -2),
+		"exp(i * pi)":        -1,
+		"log(-1)":            complex(0, math.Pi),
+		"log(i, i)":          1,
+		"add(i, 1)":          complex(1, 1),
+	}
+
+	scope := MapScope{"pi": math.Pi}
+	for expr, expected := range cases {
+		v := evalString(t, expr, scope)
+		require.IsType(t, complex128(0), v, expr)
+		assert.InDelta(t, 0, cmplx.Abs(v.(complex128)-expected), 1e-12, "%s = %v", expr, v)
+	}
+}
+
+func TestComplexFunctions(t *testing.T) {
+	cases := map[string]float64{
+		"abs(3 + 4i)": 5,
+		"arg(i)":      math.Pi / 2,
+		"arg(-2)":     math.Pi,
+		"re(2 - 3i)":  2,
+		"im(2 - 3i)":  -3,
+		"im(7)":       0,
+	}
+
+	for expr, expected := range cases {
+		assert.InDelta(t, expected, evalString(t, expr, nil), 1e-12, expr)
+	}
+}
+
+func TestComplexComparisons(t *testing.T) {
+	assert.Equal(t, true, evalString(t, "(1 + i) * (1 - i) == 2", nil))
+	assert.Equal(t, true, evalString(t, "2i != 2", nil))
+	// complex numbers without an imaginary part can still be ordered
+	assert.Equal(t, true, evalString(t, "i * i < 0", nil))
+
+	for _, expr := range []string{"i > 1", "(1 + i) % 2", "i | 1", "i!"} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, ErrInvalidOperand, expr)
+	}
+}
+
+func TestImaginaryUnitIsConfigurable(t *testing.T) {
+	node, err := Parse("2 * i")
+	require.NoError(t, err)
+
+	// the scope shadows i
+	v, err := Evaluate(node, MapScope{"i": 3.0})
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, v)
+
+	e := NewEvaluator(WithImaginaryUnit(false))
+	_, err = e.Evaluate(node, nil)
+	require.ErrorIs(t, err, ErrUndefinedSymbol)
+
+	p, err := e.Compile(node)
+	require.NoError(t, err)
+	_, err = p.Eval(nil)
+	require.ErrorIs(t, err, ErrUndefinedSymbol)
+
+	// the bytecode VM resolves i the same way
+	bc, err := CompileBytecode(node)
+	require.NoError(t, err)
+	v, err = NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	assert.Equal(t, complex(0, 2), v)
+
+	_, err = e.NewVM(bc).Run(nil)
+	require.ErrorIs(t, err, ErrUndefinedSymbol)
+}
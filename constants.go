@@ -0,0 +1,58 @@
+package mathematigo
+
+import (
+	"math"
+	"math/big"
+)
+
+// symbolConstant is a constant written as a symbol. big computes it at a
+// given precision for evaluators using NumberBigNumber, it is nil for NaN,
+// which a BigNumber cannot hold
+type symbolConstant struct {
+	float float64
+	big   func(prec uint) *big.Float
+}
+
+var (
+	piConstant  = symbolConstant{math.Pi, bigPi}
+	tauConstant = symbolConstant{2 * math.Pi, func(prec uint) *big.Float {
+		pi := bigPi(prec)
+		return pi.Mul(pi, bigInt64(2, prec))
+	}}
+	phiConstant = symbolConstant{math.Phi, func(prec uint) *big.Float {
+		phi := bigSqrt(bigInt64(5, prec))
+		phi.Add(phi, bigInt64(1, prec))
+		return phi.Quo(phi, bigInt64(2, prec))
+	}}
+	eConstant   = symbolConstant{math.E, func(prec uint) *big.Float { return bigExp(bigInt64(1, prec), prec) }}
+	infConstant = symbolConstant{math.Inf(1), func(prec uint) *big.Float { return new(big.Float).SetPrec(prec).SetInf(false) }}
+)
+
+// symbolConstants are the constants of mathjs, under their names and the
+// symbols they are written with
+var symbolConstants = map[string]symbolConstant{
+	"pi":       piConstant,
+	"π":        piConstant,
+	"tau":      tauConstant,
+	"τ":        tauConstant,
+	"phi":      phiConstant,
+	"φ":        phiConstant,
+	"e":        eConstant,
+	"ℯ":        eConstant,
+	"Infinity": infConstant,
+	"∞":        infConstant,
+	"NaN":      {float: math.NaN()},
+}
+
+// addSymbolConstants puts the symbol constants into e.constants as the
+// evaluator's number type. like in mathjs only BigNumbers change them,
+// Fractions and bigints cannot hold their values
+func (e *Evaluator) addSymbolConstants() {
+	for name, c := range symbolConstants {
+		if e.numbers == NumberBigNumber && c.big != nil {
+			e.constants[name] = c.big(e.prec)
+		} else {
+			e.constants[name] = c.float
+		}
+	}
+}
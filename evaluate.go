@@ -0,0 +1,455 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync/atomic"
+)
+
+var (
+	ErrUndefinedSymbol     = errors.New("undefined symbol")
+	ErrUndefinedFunction   = errors.New("undefined function")
+	ErrUnsupportedNode     = errors.New("unsupported node")
+	ErrUnsupportedOperator = errors.New("unsupported operator")
+	ErrCallDepth           = errors.New("maximum call depth exceeded")
+)
+
+// Value is the result of evaluating a MathNode. numbers are float64, or
+// *big.Float for BigNumbers, *big.Rat for Fractions and complex128 for
+// complex numbers, quantities with units are *Unit, strings are string,
+// booleans are bool, arrays and matrices are []Value, objects are
+// map[string]Value and null is nil.
+//
+// unlike mathjs, strings are never converted to numbers: "1" == 1 is false
+// and "1" < 2 fails with ErrInvalidOperand, where mathjs compares them as
+// numbers. format(1) == "1" compares them as strings instead
+type Value = any
+
+// Function is the signature of functions callable from expressions
+type Function func(args ...Value) (Value, error)
+
+type Evaluator struct {
+	functions map[string]Function
+	registry  *Registry
+	// constants are symbols known without a scope, such as the imaginary unit
+	constants map[string]Value
+	// units resolves otherwise undefined symbols such as cm or km/h to units
+	units bool
+	// maxCallDepth bounds recursion of functions defined in expressions
+	maxCallDepth int
+
+	numbers NumberType
+	// prec is the mantissa size in bits of BigNumber literals
+	prec uint
+}
+
+type EvalOption func(*Evaluator)
+
+// WithFunction registers fn under name for FunctionNode calls. it takes
+// precedence over functions of the same name in the registry
+func WithFunction(name string, fn Function) EvalOption {
+	return func(e *Evaluator) {
+		e.functions[name] = fn
+	}
+}
+
+// WithRegistry resolves FunctionNode calls through r instead of the standard
+// functions, see NewStandardRegistry. the registry is shared, not copied
+func WithRegistry(r *Registry) EvalOption {
+	return func(e *Evaluator) {
+		e.registry = r
+	}
+}
+
+// WithNumbers selects how number literals evaluate. with NumberBigNumber or
+// NumberFraction, FloatNodes, IntNodes and BigIntNodes evaluate to
+// BigNumbers or Fractions as well, so arithmetic and the standard functions
+// run on them. with NumberInt, integer literals and integral FloatNodes
+// evaluate to *big.Int.
+// BigNumberNodes and FractionNodes always evaluate to their own type
+func WithNumbers(t NumberType) EvalOption {
+	return func(e *Evaluator) {
+		e.numbers = t
+	}
+}
+
+// WithPrecision sets the number of significant decimal digits of BigNumber
+// literals, DefaultPrecision by default. results take the largest precision
+// of their operands
+func WithPrecision(digits int) EvalOption {
+	return func(e *Evaluator) {
+		e.prec = bigPrecisionBits(max(digits, 1))
+	}
+}
+
+// WithImaginaryUnit controls whether the symbol i is the imaginary unit,
+// which it is by default. a value for i in the scope always takes precedence,
+// turning it off only matters for expressions that should fail when i is
+// undefined
+func WithImaginaryUnit(enabled bool) EvalOption {
+	return func(e *Evaluator) {
+		if enabled {
+			e.constants["i"] = complex(0, 1)
+		} else {
+			delete(e.constants, "i")
+		}
+	}
+}
+
+// WithUnits controls whether symbols that are neither in the scope nor
+// constants resolve to units, which they do by default. without units, 5 cm
+// fails with ErrUndefinedSymbol
+func WithUnits(enabled bool) EvalOption {
+	return func(e *Evaluator) {
+		e.units = enabled
+	}
+}
+
+// DefaultMaxCallDepth is how deep functions defined in expressions may
+// recurse by default
+const DefaultMaxCallDepth = 1000
+
+// WithMaxCallDepth bounds how deep functions defined in expressions may
+// recurse before the call fails with ErrCallDepth, instead of overflowing
+// the Go stack
+func WithMaxCallDepth(depth int) EvalOption {
+	return func(e *Evaluator) {
+		e.maxCallDepth = max(depth, 1)
+	}
+}
+
+func NewEvaluator(opts ...EvalOption) *Evaluator {
+	e := &Evaluator{
+		functions:    map[string]Function{},
+		registry:     standardRegistry,
+		constants:    map[string]Value{"i": complex(0, 1)},
+		units:        true,
+		maxCallDepth: DefaultMaxCallDepth,
+		prec:         defaultBigPrec,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	// after the options, which pick the number type and precision
+	e.addSymbolConstants()
+
+	return e
+}
+
+// Evaluate computes the value of node using the default Evaluator
+func Evaluate(node MathNode, scope Scope) (Value, error) {
+	return NewEvaluator().Evaluate(node, scope)
+}
+
+// Evaluate computes the value of node. symbols are resolved from scope, which
+// may be nil. a BlockNode evaluates to the value of its last visible
+// statement, or null when every statement ends in a semicolon
+func (e *Evaluator) Evaluate(node MathNode, scope Scope) (Value, error) {
+	if scope == nil {
+		scope = MapScope{}
+	}
+
+	return e.eval(node, scope)
+}
+
+func (e *Evaluator) eval(node MathNode, scope Scope) (Value, error) {
+	switch n := node.(type) {
+	case *FloatNode:
+		return e.number(float64(*n)), nil
+	case *IntNode:
+		return e.integer(big.NewInt(int64(*n))), nil
+	case *BigIntNode:
+		return e.integer(n.BigInt()), nil
+	case *BigNumberNode:
+		return parseBig(string(*n), e.prec)
+	case *FractionNode:
+		return parseRat(string(*n))
+	case *BooleanNode:
+		return bool(*n), nil
+	case *ConstantNode:
+		return string(*n), nil
+	case *NullNode:
+		return nil, nil
+	case *SymbolNode:
+		return e.symbol(n.Name, scope)
+	case *ParenthesisNode:
+		return e.eval(n.Content, scope)
+	case *OperatorNode:
+		return e.evalOperator(n, scope)
+	case *FunctionNode:
+		return e.evalFunction(n, scope)
+	case *AssignmentNode:
+		v, err := e.eval(n.Value, scope)
+		if err != nil {
+			return nil, err
+		}
+		if err := scope.Set(n.Object.Name, v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case *ConditionalNode:
+		cond, err := e.eval(n.Condition, scope)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := truthy(cond)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return e.eval(n.TrueExpr, scope)
+		}
+		return e.eval(n.FalseExpr, scope)
+	case *ArrayNode:
+		items, err := e.evalArgs(n.Items, scope)
+		if err != nil {
+			return nil, err
+		}
+		return newArray(items)
+	case *RangeNode:
+		start, err := e.eval(n.Start, scope)
+		if err != nil {
+			return nil, err
+		}
+		end, err := e.eval(n.End, scope)
+		if err != nil {
+			return nil, err
+		}
+		step := e.number(1)
+		if n.Step != nil {
+			if step, err = e.eval(n.Step, scope); err != nil {
+				return nil, err
+			}
+		}
+		return newRange(start, end, step)
+	case *ObjectNode:
+		out := make(map[string]Value, len(n.Properties))
+		for _, prop := range n.Properties {
+			v, err := e.eval(prop.Value, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[prop.Key] = v
+		}
+		return out, nil
+	case *AccessorNode:
+		obj, err := e.eval(n.Object, scope)
+		if err != nil {
+			return nil, err
+		}
+		dims, err := e.evalArgs(n.Index.Dimensions, scope)
+		if err != nil {
+			return nil, err
+		}
+		return access(obj, dims)
+	case *FunctionAssignmentNode:
+		return e.defineFunction(n, func(scope Scope) (Value, error) { return e.eval(n.Expr, scope) }, scope)
+	case *BlockNode:
+		var out Value
+		for i, block := range n.Blocks {
+			v, err := e.eval(block, scope)
+			if err != nil {
+				return nil, err
+			}
+			if n.Visible(i) {
+				out = v
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedNode, node)
+	}
+}
+
+// symbol resolves name from the scope and then from the builtins
+func (e *Evaluator) symbol(name string, scope Scope) (Value, error) {
+	if v, ok := scope.Get(name); ok {
+		return v, nil
+	}
+	if v, ok := e.builtin(name); ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUndefinedSymbol, name)
+}
+
+// builtin is the value of a symbol the scope does not define: a constant or,
+// failing that, a unit
+func (e *Evaluator) builtin(name string) (Value, bool) {
+	if v, ok := e.constants[name]; ok {
+		return v, true
+	}
+	if e.units {
+		if u, ok := lookupUnit(name); ok {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// number converts a numeric literal to the evaluator's number type
+func (e *Evaluator) number(x float64) Value {
+	switch e.numbers {
+	case NumberBigNumber:
+		if b, ok := toBig(x, e.prec); ok {
+			return b
+		}
+	case NumberFraction:
+		if r, ok := toRat(x); ok {
+			return r
+		}
+	case NumberInt:
+		if x == math.Trunc(x) && !math.IsInf(x, 0) {
+			i, _ := new(big.Float).SetFloat64(x).Int(nil)
+			return i
+		}
+	}
+	return x
+}
+
+// integer is number for integer literals, which BigNumbers, Fractions and
+// bigints hold exactly even past 2^53
+func (e *Evaluator) integer(x *big.Int) Value {
+	switch e.numbers {
+	case NumberBigNumber:
+		return new(big.Float).SetPrec(e.prec).SetInt(x)
+	case NumberFraction:
+		return new(big.Rat).SetInt(x)
+	case NumberInt:
+		return x
+	}
+	f, _ := toNumber(x)
+	return f
+}
+
+func (e *Evaluator) evalOperator(n *OperatorNode, scope Scope) (Value, error) {
+	if decides, ok := shortCircuits[n.Fn]; ok && len(n.Args) == 2 {
+		left, err := e.eval(n.Args[0], scope)
+		if err != nil {
+			return nil, err
+		}
+		x, err := truthy(left)
+		if err != nil {
+			return nil, err
+		}
+		if x == decides {
+			return x, nil
+		}
+		right, err := e.eval(n.Args[1], scope)
+		if err != nil {
+			return nil, err
+		}
+		y, err := truthy(right)
+		if err != nil {
+			return nil, err
+		}
+		return y, nil
+	}
+
+	args, err := e.evalArgs(n.Args, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(args) {
+	case 1:
+		if op, ok := unaryOperators[n.Fn]; ok {
+			return op(args[0])
+		}
+	case 2:
+		if op, ok := binaryOperators[n.Fn]; ok {
+			return op(args[0], args[1])
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s with %d argument(s)", ErrUnsupportedOperator, n.Fn, len(args))
+}
+
+func (e *Evaluator) evalFunction(n *FunctionNode, scope Scope) (Value, error) {
+	fn, err := e.lookupFunction(n.Fn.Name, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := e.evalArgs(n.Args, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return fn(args...)
+}
+
+// lookupFunction prefers functions stored in the scope over registered ones,
+// so callers can shadow a registered function per evaluation
+func (e *Evaluator) lookupFunction(name string, scope Scope) (Function, error) {
+	if v, ok := scope.Get(name); ok {
+		if fn, ok := v.(Function); ok {
+			return fn, nil
+		}
+	}
+
+	if fn := e.function(name); fn != nil {
+		return fn, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUndefinedFunction, name)
+}
+
+// defineFunction stores the Function that a FunctionAssignmentNode defines in
+// scope. each call evaluates body in a ChildScope of scope holding the
+// arguments, so the body sees later changes to scope but cannot modify it
+func (e *Evaluator) defineFunction(n *FunctionAssignmentNode, body evalFunc, scope Scope) (Value, error) {
+	name, params, maxDepth := n.Name, n.Params, int32(e.maxCallDepth)
+
+	// depth counts the calls in progress, which recursion keeps growing
+	var depth atomic.Int32
+	fn := Function(func(args ...Value) (Value, error) {
+		if len(args) != len(params) {
+			return nil, fmt.Errorf("%w: %s expects %d arguments, got %d", ErrArity, name, len(params), len(args))
+		}
+
+		defer depth.Add(-1)
+		if depth.Add(1) > maxDepth {
+			return nil, fmt.Errorf("%w: %s called itself more than %d times", ErrCallDepth, name, maxDepth)
+		}
+
+		local := NewChildScope(scope)
+		for i, param := range params {
+			if err := local.Set(param, args[i]); err != nil {
+				return nil, err
+			}
+		}
+		return body(local)
+	})
+
+	if err := scope.Set(name, fn); err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+// function resolves name against WithFunction and then the registry. it
+// returns nil when neither knows the name
+func (e *Evaluator) function(name string) Function {
+	if fn, ok := e.functions[name]; ok {
+		return fn
+	}
+	if fn, ok := e.registry.Lookup(name); ok {
+		return fn
+	}
+	return nil
+}
+
+func (e *Evaluator) evalArgs(nodes []MathNode, scope Scope) ([]Value, error) {
+	args := make([]Value, 0, len(nodes))
+	for _, node := range nodes {
+		v, err := e.eval(node, scope)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	return args, nil
+}
@@ -0,0 +1,716 @@
+package mathematigo
+
+import (
+	"math"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evalString(t *testing.T, expr string, scope Scope) Value {
+	t.Helper()
+
+	node, err := Parse(expr)
+	require.NoError(t, err)
+
+	v, err := Evaluate(node, scope)
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestEvaluateArithmetic(t *testing.T) {
+	cases := map[string]Value{
+		"1 + 2":       3.0,
+		"7 - 10":      -3.0,
+		"2 * 3 + 4":   10.0,
+		"2 * (3 + 4)": 14.0,
+		"9 / 2":       4.5,
+		"2 ^ 3 ^ 2":   512.0,
+		"7 % 3":       1.0,
+		"-7 % 3":      2.0,
+		"7 % -3":      -2.0,
+		"5 % 0":       5.0,
+		"5!":          120.0,
+		"0!":          1.0,
+		"-3!":         -6.0,
+		"6 | 3":       7.0,
+		"6 & 3":       2.0,
+		"2 a":         10.0,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, MapScope{"a": 5.0}), expr)
+	}
+}
+
+func TestEvaluateComparisons(t *testing.T) {
+	cases := map[string]Value{
+		"1 < 2":             true,
+		"2 <= 2":            true,
+		"3 > 2":             true,
+		"1 >= 2":            false,
+		"1 == 1":            true,
+		"1 != 1":            false,
+		"0.1 + 0.2 == 0.3":  true,
+		`"gold" == "gold"`:  true,
+		`"gold" != "steel"`: true,
+		`"a" < "b"`:         true,
+		// strings are never converted to numbers, unlike in mathjs
+		`"1" == 1`:         false,
+		`"1" != 1`:         true,
+		`1 == "1"`:         false,
+		`"1" == "1.0"`:     false,
+		`format(1) == "1"`: true,
+		"null == null":     true,
+		"true == 1":        true,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, nil), expr)
+	}
+
+	// nor are they ordered against numbers
+	for _, expr := range []string{`"1" < 2`, `2 >= "1"`} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, ErrInvalidOperand, expr)
+	}
+}
+
+func TestEvaluateLiterals(t *testing.T) {
+	assert.Equal(t, "abc", evalString(t, `"abc"`, nil))
+	assert.Equal(t, true, evalString(t, "true", nil))
+	assert.Nil(t, evalString(t, "null", nil))
+
+	v, err := Evaluate(NewIntNode(3), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, v)
+}
+
+func TestEvaluateNaNComparisons(t *testing.T) {
+	scope := MapScope{"n": math.NaN()}
+
+	assert.Equal(t, false, evalString(t, "n > 1", scope))
+	assert.Equal(t, false, evalString(t, "n <= 1", scope))
+	assert.Equal(t, false, evalString(t, "n == n", scope))
+	assert.Equal(t, true, evalString(t, "n != n", scope))
+}
+
+func TestEvaluateBlockReturnsLastStatement(t *testing.T) {
+	assert.Equal(t, 4.0, evalString(t, "1 + 1\n2 + 2", nil))
+	assert.Equal(t, 2.0, evalString(t, "\n1 + 1\n", nil))
+}
+
+func TestEvaluateUndefinedSymbol(t *testing.T) {
+	node, err := Parse("x + 1")
+	require.NoError(t, err)
+
+	v, err := Evaluate(node, nil)
+	require.ErrorIs(t, err, ErrUndefinedSymbol)
+	require.Nil(t, v)
+}
+
+func TestEvaluateFunctions(t *testing.T) {
+	double := Function(func(args ...Value) (Value, error) {
+		return args[0].(float64) * 2, nil
+	})
+
+	node, err := Parse("double(x) + 1")
+	require.NoError(t, err)
+
+	e := NewEvaluator(WithFunction("double", double))
+	v, err := e.Evaluate(node, MapScope{"x": 4.0})
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, v)
+
+	// functions in the scope shadow registered ones
+	triple := Function(func(args ...Value) (Value, error) {
+		return args[0].(float64) * 3, nil
+	})
+	v, err = e.Evaluate(node, MapScope{"x": 4.0, "double": triple})
+	require.NoError(t, err)
+	assert.Equal(t, 13.0, v)
+
+	v, err = Evaluate(node, MapScope{"x": 4.0})
+	require.ErrorIs(t, err, ErrUndefinedFunction)
+	require.Nil(t, v)
+}
+
+func TestEvaluateInvalidOperands(t *testing.T) {
+	for _, expr := range []string{`"a" + 1`, `1.5 | 1`, `(-1)!`, `"a" > 1`} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, ErrInvalidOperand, expr)
+	}
+}
+
+func TestEvaluateUnsupportedOperator(t *testing.T) {
+	_, err := Evaluate(NewOperatorNode("?", "unknown", NewFloatNode(1), NewFloatNode(2)), nil)
+	require.ErrorIs(t, err, ErrUnsupportedOperator)
+}
+
+func TestEvaluateAssignment(t *testing.T) {
+	node, err := Parse("price = 2.5\nqty = 4\ntotal = price * qty\ntotal - 1")
+	require.NoError(t, err)
+
+	scope := MapScope{}
+	v, err := Evaluate(node, scope)
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, v)
+	assert.Equal(t, MapScope{"price": 2.5, "qty": 4.0, "total": 10.0}, scope)
+
+	// an assignment evaluates to the assigned value
+	assert.Equal(t, 3.0, evalString(t, "a = b = 3", nil))
+}
+
+func TestEvaluateAssignmentReadOnlyScope(t *testing.T) {
+	scope, err := NewStructScope(struct{ X float64 }{X: 1})
+	require.NoError(t, err)
+
+	node, err := Parse("X = 2")
+	require.NoError(t, err)
+
+	_, err = Evaluate(node, scope)
+	require.ErrorIs(t, err, ErrReadOnlyScope)
+}
+
+func TestEvaluateFunctionAssignment(t *testing.T) {
+	cases := map[string]float64{
+		"area(w, h) = w * h\narea(3, 4)":           12,
+		"f(x) = x ^ 2\ng(x) = f(x) + 1\ng(3)":      10,
+		"x = 10\nf(x) = x * 2\nf(3) + x":           16,
+		"k = 2\nscale(x) = k * x\nk = 3\nscale(2)": 6,
+		"f(x) = y = x\nf(4)\ny = 1\nf(5) + y":      6,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, nil), expr)
+	}
+}
+
+func TestEvaluateFunctionAssignmentErrors(t *testing.T) {
+	cases := map[string]error{
+		"f(x) = f(x)\nf(1)":     ErrCallDepth,
+		"f(x, y) = x + y\nf(1)": ErrArity,
+		"f(x) = x + nope\nf(1)": ErrUndefinedSymbol,
+	}
+
+	for _, e := range []*Evaluator{NewEvaluator(), NewEvaluator(WithMaxCallDepth(10))} {
+		for expr, expected := range cases {
+			node, err := Parse(expr)
+			require.NoError(t, err)
+
+			_, err = e.Evaluate(node, nil)
+			require.ErrorIs(t, err, expected, expr)
+
+			p, err := e.Compile(node)
+			require.NoError(t, err)
+			_, err = p.Eval(nil)
+			require.ErrorIs(t, err, expected, expr)
+		}
+	}
+
+	node, err := Parse("f(x) = x")
+	require.NoError(t, err)
+	_, err = CompileBytecode(node)
+	require.ErrorIs(t, err, ErrUnsupportedNode)
+}
+
+func TestEvaluateArrays(t *testing.T) {
+	assert.Equal(t, []Value{1.0, 2.0, 5.0}, evalString(t, "[1, 2, x + 3]", MapScope{"x": 2.0}))
+	assert.Equal(t, []Value{[]Value{1.0, 2.0}, []Value{3.0, 4.0}}, evalString(t, "[1, 2; 3, 4]", nil))
+	assert.Equal(t, []Value{}, evalString(t, "[]", nil))
+	assert.Equal(t, []Value{[]Value{1.0}}, evalString(t, "[[1]]", nil))
+
+	for _, expr := range []string{"[[1, 2], [3]]", "[1, [2]]", "[[1], 2]", "[1, 2; 3]"} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, ErrDimensionMismatch, expr)
+	}
+}
+
+func TestEvaluateAccessors(t *testing.T) {
+	type customer struct {
+		Tier string `math:"tier"`
+	}
+
+	scope := MapScope{
+		"order": map[string]any{
+			"customer": map[string]any{"tier": "gold"},
+			"items":    []any{10.0, 20.0, 30.0},
+		},
+		"m":     []Value{[]Value{1.0, 2.0, 3.0}, []Value{4.0, 5.0, 6.0}},
+		"buyer": customer{Tier: "silver"},
+		"inner": MapScope{"x": 1.0},
+	}
+
+	cases := map[string]Value{
+		"order.customer.tier":       "gold",
+		`order["customer"]["tier"]`: "gold",
+		"order.items[2]":            20.0,
+		"order.items[3] - 1":        29.0,
+		"m[2, 3]":                   6.0,
+		"m[1][2]":                   2.0,
+		"m[2]":                      []Value{4.0, 5.0, 6.0},
+		"[7, 8, 9][3]":              9.0,
+		`"abc"[2]`:                  "b",
+		"buyer.tier":                "silver",
+		"inner.x":                   1.0,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, scope), expr)
+
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		p, err := Compile(node)
+		require.NoError(t, err)
+		v, err := p.Eval(scope)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}
+
+func TestEvaluateAccessorErrors(t *testing.T) {
+	scope := MapScope{
+		"order": map[string]any{"items": []any{10.0}},
+		"x":     2.0,
+	}
+
+	cases := map[string]error{
+		"order.items[0]":   ErrIndexOutOfRange,
+		"order.items[2]":   ErrIndexOutOfRange,
+		"order.items[1.5]": ErrInvalidIndex,
+		`order.items["a"]`: ErrInvalidIndex,
+		"order[1]":         ErrInvalidIndex,
+		"order.missing":    ErrUndefinedProperty,
+		"x[1]":             ErrInvalidIndex,
+		"nope.a":           ErrUndefinedSymbol,
+	}
+
+	for expr, expected := range cases {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		_, err = Evaluate(node, scope)
+		require.ErrorIs(t, err, expected, expr)
+	}
+}
+
+func TestEvaluateObjects(t *testing.T) {
+	v := evalString(t, `{name: "a", limits: {min: 1, max: x * 10}}`, MapScope{"x": 2.0})
+	assert.Equal(t, map[string]Value{"name": "a", "limits": map[string]Value{"min": 1.0, "max": 20.0}}, v)
+	assert.Equal(t, `{"limits": {"max": 20, "min": 1}, "name": "a"}`, Format(v))
+
+	assert.Equal(t, 20.0, evalString(t, "r = {limits: {min: 1, max: 20}}\nr.limits.max", nil))
+
+	node, err := Parse("{a: [1, 2], b: a}")
+	require.NoError(t, err)
+	p, err := Compile(node)
+	require.NoError(t, err)
+	v, err = p.Eval(MapScope{"a": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Value{"a": []Value{1.0, 2.0}, "b": "x"}, v)
+}
+
+func TestEvaluateRanges(t *testing.T) {
+	cases := map[string][]Value{
+		"1:5":       {1.0, 2.0, 3.0, 4.0, 5.0},
+		"1:1":       {1.0},
+		"5:1":       {},
+		"1:0.5":     {},
+		"5:-2:1":    {5.0, 3.0, 1.0},
+		"1:-1:5":    {},
+		"0:0.5:2":   {0.0, 0.5, 1.0, 1.5, 2.0},
+		"-1:1":      {-1.0, 0.0, 1.0},
+		"1.5:3":     {1.5, 2.5},
+		"0:0.1:0.3": {0.0, 0.1, 0.2, 0.30000000000000004},
+		// large magnitudes, where the values are nearly equal to each other
+		"1e20:1e20":     {1e20},
+		"1e16:1e16+4":   {1e16, 1e16, 1e16 + 2, 1e16 + 4, 1e16 + 4},
+		"1e16:2:1e16+4": {1e16, 1e16 + 2, 1e16 + 4},
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, nil), expr)
+	}
+
+	assert.Equal(t, 5050.0, evalString(t, "sum(1:n)", MapScope{"n": 100.0}))
+	assert.Equal(t, "[1/3, 4/3, 7/3]", Format(evalFraction(t, "1/3:1:7/3", nil)))
+	assert.Equal(t, "[9007199254740993, 9007199254740994, 9007199254740995]", Format(evalInt(t, "9007199254740993:9007199254740995", nil)))
+
+	for expr, expected := range map[string]error{
+		"1:0:3":       ErrArgumentValue,
+		"1:1e9":       ErrArgumentValue,
+		`1:"a"`:       ErrInvalidOperand,
+		"1:i":         ErrInvalidOperand,
+		"(1 m):(2 m)": ErrInvalidOperand,
+	} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, expected, expr)
+	}
+}
+
+func TestEvaluateRangeIndices(t *testing.T) {
+	scope := MapScope{
+		"a": []Value{10.0, 20.0, 30.0, 40.0},
+		"m": []Value{[]Value{1.0, 2.0, 3.0}, []Value{4.0, 5.0, 6.0}},
+	}
+
+	cases := map[string]Value{
+		"a[2:3]":    []Value{20.0, 30.0},
+		"a[[4, 1]]": []Value{40.0, 10.0},
+		"m[2, 1:3]": []Value{[]Value{4.0, 5.0, 6.0}},
+		// a single index is the row, not the 1×3 subset of mathjs
+		"m[2]":                     []Value{4.0, 5.0, 6.0},
+		"[[1, 2], [3, 4]][1]":      []Value{1.0, 2.0},
+		"[[1, 2], [3, 4]][1, 1:2]": []Value{[]Value{1.0, 2.0}},
+		"m[1:2, 2]":                []Value{[]Value{2.0}, []Value{5.0}},
+		`"abcd"[2:3]`:              "bc",
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, scope), expr)
+	}
+}
+
+func TestEvaluateArrayFunctions(t *testing.T) {
+	cases := map[string]float64{
+		"sum(1, 2, 3)":      6,
+		"sum([1, 2; 3, 4])": 10,
+		"sum([])":           0,
+		"max([3, 7, 2])":    7,
+		"min(2:2:10)":       2,
+		"max([1, 5; 9, 2])": 9,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, nil), expr)
+	}
+
+	node, err := Parse("max([])")
+	require.NoError(t, err)
+	_, err = Evaluate(node, nil)
+	require.ErrorIs(t, err, ErrArgumentValue)
+
+	// concat joins arrays along their last dimension
+	for expr, expected := range map[string]string{
+		"concat([1], [2])":             "[1, 2]",
+		"concat([1, 2], [], [3], 4:5)": "[1, 2, 3, 4, 5]",
+		"concat([1, 2; 3, 4], [5; 6])": "[[1, 2, 5], [3, 4, 6]]",
+		`concat(["a"], [true])`:        `["a", true]`,
+		"concat([[1, 2]], [[3, 4]])":   "[[1, 2, 3, 4]]",
+	} {
+		assert.Equal(t, expected, Format(evalString(t, expr, nil)), expr)
+	}
+
+	for expr, expected := range map[string]error{
+		"concat([1, 2; 3, 4], [5])":       ErrDimensionMismatch,
+		"concat([1, 2; 3, 4], [5; 6; 7])": ErrDimensionMismatch,
+		`concat([1], "a")`:                ErrArgumentType,
+	} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, expected, expr)
+	}
+}
+
+func TestEvaluateConditional(t *testing.T) {
+	expr := `tier == "gold" ? price * 0.9 : price`
+	assert.Equal(t, 90.0, evalString(t, expr, MapScope{"tier": "gold", "price": 100.0}))
+	assert.Equal(t, 100.0, evalString(t, expr, MapScope{"tier": "silver", "price": 100.0}))
+
+	cases := map[string]Value{
+		"1 ? 2 : 3":           2.0,
+		"0 ? 2 : 3":           3.0,
+		"NaN ? 2 : 3":         3.0,
+		`"" ? 2 : 3`:          3.0,
+		`"x" ? 2 : 3`:         2.0,
+		"null ? 2 : 3":        3.0,
+		"i ? 2 : 3":           2.0,
+		"(0 cm) ? 2 : 3":      3.0,
+		"a ? 1 : b ? 2 : 3":   2.0,
+		"x = a ? 5 : 6\nx":    6.0,
+		"b ? (1:3) : 0":       []Value{1.0, 2.0, 3.0},
+		"a ? 1 : 2:4":         []Value{2.0, 3.0, 4.0},
+		"b ? a ? 1 : 2 : 3":   2.0,
+		"(b ? 1 : 2) ? 4 : 5": 4.0,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, MapScope{"a": false, "b": true, "NaN": math.NaN()}), expr)
+	}
+}
+
+func TestEvaluateConditionalIsLazy(t *testing.T) {
+	e := NewEvaluator(WithFunction("fail", func(args ...Value) (Value, error) {
+		return nil, ErrArgumentValue
+	}))
+
+	for _, expr := range []string{"x > 0 ? 1 / x : fail()", "x <= 0 ? fail() : 1 / x"} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		v, err := e.Evaluate(node, MapScope{"x": 4.0})
+		require.NoError(t, err, expr)
+		assert.Equal(t, 0.25, v, expr)
+	}
+
+	node, err := Parse("[1] ? 1 : 2")
+	require.NoError(t, err)
+	_, err = Evaluate(node, nil)
+	require.ErrorIs(t, err, ErrInvalidOperand)
+}
+
+func TestEvaluateLogicalOperators(t *testing.T) {
+	cases := map[string]Value{
+		"true and false":      false,
+		"1 and 2":             true,
+		"0 or null":           false,
+		`"" or "x"`:           true,
+		"true xor true":       false,
+		"1 xor 0":             true,
+		"not 0":               true,
+		"not not 5":           true,
+		"not i":               false,
+		"a > 1 and b < 2":     true,
+		"a > 1 and not b < 2": true, // (not b) < 2
+		"a < 1 or b < 2":      true,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, MapScope{"a": 3.0, "b": 1.0}), expr)
+	}
+}
+
+func TestEvaluateLogicalShortCircuit(t *testing.T) {
+	e := NewEvaluator(WithFunction("fail", func(args ...Value) (Value, error) {
+		return nil, ErrArgumentValue
+	}))
+
+	for _, expr := range []string{"false and fail()", "1 or fail()", "x == 0 or 1 / x > 0"} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+
+		v, err := e.Evaluate(node, MapScope{"x": 0.0})
+		require.NoError(t, err, expr)
+		assert.Equal(t, expr != "false and fail()", v, expr)
+
+		p, err := e.Compile(node)
+		require.NoError(t, err, expr)
+		v, err = p.Eval(MapScope{"x": 0.0})
+		require.NoError(t, err, expr)
+		assert.Equal(t, expr != "false and fail()", v, expr)
+	}
+
+	// xor needs both sides
+	node, err := Parse("true xor fail()")
+	require.NoError(t, err)
+	_, err = e.Evaluate(node, nil)
+	require.ErrorIs(t, err, ErrArgumentValue)
+
+	node, err = Parse("[1] and true")
+	require.NoError(t, err)
+	_, err = e.Evaluate(node, nil)
+	require.ErrorIs(t, err, ErrInvalidOperand)
+}
+
+func TestEvaluateBitwiseOperators(t *testing.T) {
+	cases := map[string]Value{
+		"5 ^| 3":                  6.0,
+		"~5":                      -6.0,
+		"~-1":                     0.0,
+		"1 << 3":                  8.0,
+		"-16 >> 2":                -4.0,
+		"-1 >>> 60":               15.0,
+		"16 >>> 2":                4.0,
+		"-8 >>> 1":                9223372036854775804.0,
+		"-8 >>> 0":                -8.0,
+		"1 >>> 32":                0.0,
+		"5 >> 33":                 0.0,
+		"5 >>> 33":                0.0,
+		"(2^40) >> 0":             1099511627776.0,
+		"(2^40) >>> 0":            1099511627776.0,
+		"(2^40 + 8) >>> 3":        137438953473.0,
+		"(flags << 40) >>> 41":    5.0,
+		"(1 << 40) & (3 << 39)":   1099511627776.0,
+		"(1 << 40) | 1":           1099511627777.0,
+		"(3 << 40) ^| (1 << 40)":  2199023255552.0,
+		"(flags & (1 << 3)) != 0": true,
+		"flags ^| 1":              11.0,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, MapScope{"flags": 10.0}), expr)
+	}
+
+	assert.Equal(t, "1267650600228229401496703205376", Format(evalFraction(t, "1 << 100", nil)))
+	assert.Equal(t, "-3", Format(evalFraction(t, "-5 >> 1", nil)))
+	assert.Equal(t, "-8", Format(evalFraction(t, "~7", nil)))
+
+	for expr, expected := range map[string]error{
+		"1.5 << 1":   ErrInvalidOperand,
+		"1 >> 0.5":   ErrInvalidOperand,
+		"~0.5":       ErrInvalidOperand,
+		"1 ^| i":     ErrInvalidOperand,
+		`"a" << 1`:   ErrInvalidOperand,
+		"1 << -1":    ErrArgumentValue,
+		"1 << 1e6":   ErrArgumentValue,
+		"(1 m) >> 1": ErrInvalidOperand,
+	} {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, expected, expr)
+	}
+
+	// there is no fixed width to shift zeros into
+	node, err := Parse("8 >>> 1", ParseNumbers(NumberFraction))
+	require.NoError(t, err)
+	_, err = Evaluate(node, nil)
+	require.ErrorIs(t, err, ErrInvalidOperand)
+}
+
+func TestEvaluateSemicolons(t *testing.T) {
+	scope := MapScope{}
+	assert.Equal(t, 2.0, evalString(t, "a = 1; b = 2", scope))
+	assert.Equal(t, MapScope{"a": 1.0, "b": 2.0}, scope)
+
+	assert.Equal(t, 10.0, evalString(t, "x = 5; x * 2", nil))
+	assert.Equal(t, 1.0, evalString(t, "1\n2;", nil))
+	assert.Nil(t, evalString(t, "a = 1; b = 2;", nil))
+}
+
+func TestEvaluateAllHiddenBlock(t *testing.T) {
+	node, err := Parse("a = 1; b = a + 1;")
+	require.NoError(t, err)
+
+	// only the last visible result is kept, so there is none to return
+	scope := MapScope{}
+	v, err := Evaluate(node, scope)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	assert.Equal(t, MapScope{"a": 1.0, "b": 2.0}, scope)
+
+	p, err := Compile(node)
+	require.NoError(t, err)
+	v, err = p.Eval(MapScope{})
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	bc, err := CompileBytecode(node)
+	require.NoError(t, err)
+	v, err = NewVM(bc).Run(MapScope{})
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestEvaluateComments(t *testing.T) {
+	src := "# discount rules\nrate = 0.1 # ten percent\nprice * (1 - rate) # net"
+	assert.Equal(t, 90.0, evalString(t, src, MapScope{"price": 100.0}))
+
+	node, err := Parse(src, ParseComments())
+	require.NoError(t, err)
+	v, err := Evaluate(node, MapScope{"price": 100.0})
+	require.NoError(t, err)
+	assert.Equal(t, 90.0, v)
+}
+
+func TestEvaluateRadixIntegers(t *testing.T) {
+	cases := map[string]Value{
+		"0x1F + 0o17 + 0b1011": 57.0,
+		"0xFFi8":               -1.0,
+		"flags & 0b1000":       8.0,
+		"0x1i":                 complex(0, 1),
+		"2 0x10":               32.0,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, MapScope{"flags": 10.0}), expr)
+	}
+
+	// BigNumbers and Fractions hold them exactly
+	node, err := Parse("0x7FFFFFFFFFFFFFFF")
+	require.NoError(t, err)
+	v, err := NewEvaluator(WithNumbers(NumberFraction)).Evaluate(node, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "9223372036854775807", Format(v))
+}
+
+func TestEvaluateUnicodeIdentifiers(t *testing.T) {
+	cases := map[string]Value{
+		"π * r^2":          math.Pi * 4,
+		"2π":               2 * math.Pi,
+		"τ / π":            2.0,
+		"ℯ":                math.E,
+		"φ^2 - φ":          1.0,
+		"-∞ < 0":           true,
+		"α + β":            3.0,
+		"$total * 2":       20.0,
+		"Größe + 1":        8.0,
+		"f(θ) = θ^2; f(3)": 9.0,
+	}
+
+	scope := func() Scope {
+		return MapScope{"r": 2.0, "α": 1.0, "β": 2.0, "$total": 10.0, "Größe": 7.0}
+	}
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, scope()), expr)
+	}
+
+	// the scope shadows the constants
+	assert.Equal(t, 3.0, evalString(t, "π", MapScope{"π": 3.0}))
+
+	// BigNumbers compute them at the evaluator's precision
+	node, err := Parse("π")
+	require.NoError(t, err)
+	v, err := NewEvaluator(WithNumbers(NumberBigNumber), WithPrecision(30)).Evaluate(node, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "3.14159265358979323846264338328", Format(v))
+
+	// any letters, with a custom test
+	node, err = Parse("数量 * 2", ParseIdentifiers(func(r rune) bool { return IsAlpha(r) || unicode.IsLetter(r) }))
+	require.NoError(t, err)
+	v, err = Evaluate(node, MapScope{"数量": 4.0})
+	require.NoError(t, err)
+	assert.Equal(t, 8.0, v)
+
+	_, err = Parse("数量 * 2")
+	var scanErr *ScanErr
+	require.ErrorAs(t, err, &scanErr)
+}
+
+func TestEvaluateConstants(t *testing.T) {
+	cases := map[string]Value{
+		"2 * pi":         2 * math.Pi,
+		"tau == 2pi":     true,
+		"phi == φ":       true,
+		"log(e)":         1.0,
+		"-Infinity < 0":  true,
+		"Infinity == ∞":  true,
+		"NaN == NaN":     false,
+		"e ^ 0 + pi * 0": 1.0,
+		"sin(pi / 2)":    1.0,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, nil), expr)
+	}
+	assert.True(t, math.IsNaN(evalString(t, "NaN", nil).(float64)))
+
+	z, ok := evalString(t, "exp(i * pi)", nil).(complex128)
+	require.True(t, ok)
+	assert.InDelta(t, -1, real(z), 1e-15)
+	assert.InDelta(t, 0, imag(z), 1e-15)
+
+	// the scope shadows them, so e can still be a variable
+	assert.Equal(t, 3.0, evalString(t, "e + 1", MapScope{"e": 2.0}))
+}
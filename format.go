@@ -0,0 +1,174 @@
+package mathematigo
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FractionFormat selects how Format prints Fractions
+type FractionFormat int
+
+const (
+	// FractionRatio prints Fractions as a/b, e.g. 1/3
+	FractionRatio FractionFormat = iota
+	// FractionDecimal prints Fractions as decimals with the repeating digits
+	// in parentheses, e.g. 0.(3)
+	FractionDecimal
+)
+
+// maxFractionDigits bounds the decimals FractionDecimal prints when the
+// repeating part is longer than that
+const maxFractionDigits = DefaultPrecision
+
+type formatter struct {
+	fractions FractionFormat
+}
+
+type FormatOption func(*formatter)
+
+func FormatFractions(f FractionFormat) FormatOption {
+	return func(fm *formatter) {
+		fm.fractions = f
+	}
+}
+
+// Format prints a Value the way mathjs does: numbers use the fewest digits
+// that read back to the same value, BigNumbers print all the digits of their
+// precision and strings are quoted
+func Format(v Value, opts ...FormatOption) string {
+	f := &formatter{}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return formatFloat(x)
+	case *big.Float:
+		if x.IsInf() {
+			return formatFloat(math.Inf(x.Sign()))
+		}
+		return x.Text('g', bigDecimalDigits(x.Prec()))
+	case *big.Int:
+		return x.String()
+	case *big.Rat:
+		if f.fractions == FractionDecimal {
+			return ratDecimal(x)
+		}
+		return x.RatString()
+	case complex128:
+		return formatComplex(x)
+	case bool:
+		return strconv.FormatBool(x)
+	case string:
+		return quoteString(x)
+	case Function:
+		return "function"
+	case *Unit:
+		return x.String()
+	case []Value:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			parts = append(parts, Format(item, opts...))
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]Value:
+		// maps have no order, the keys are sorted to keep the output stable
+		keys := slices.Sorted(maps.Keys(x))
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, quoteString(k)+": "+Format(x[k], opts...))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatFloat(x float64) string {
+	switch {
+	case math.IsInf(x, 1):
+		return "Infinity"
+	case math.IsInf(x, -1):
+		return "-Infinity"
+	default:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+}
+
+// formatComplex prints z as a + bi, leaving out a zero real or imaginary
+// part, e.g. 2 - 3i, 2i or 2
+func formatComplex(z complex128) string {
+	re, im := real(z), imag(z)
+	if im == 0 {
+		return formatFloat(re)
+	}
+
+	imText := func(im float64) string {
+		if im == 1 {
+			return "i"
+		}
+		return formatFloat(im) + "i"
+	}
+
+	switch {
+	case re == 0 && im == -1:
+		return "-i"
+	case re == 0:
+		return imText(im)
+	case im < 0:
+		return formatFloat(re) + " - " + imText(-im)
+	default:
+		return formatFloat(re) + " + " + imText(im)
+	}
+}
+
+// ratDecimal does the long division of r, putting the first repeating digits
+// in parentheses once a remainder comes back
+func ratDecimal(r *big.Rat) string {
+	var sb strings.Builder
+	if r.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+
+	num := new(big.Int).Abs(r.Num())
+	q, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	sb.WriteString(q.String())
+	if rem.Sign() == 0 {
+		return sb.String()
+	}
+
+	// seen maps each remainder to the index of the digit it produced
+	seen := map[string]int{}
+	var digits []byte
+	ten := big.NewInt(10)
+	digit := new(big.Int)
+
+	for rem.Sign() != 0 && len(digits) < maxFractionDigits {
+		key := rem.String()
+		if at, ok := seen[key]; ok {
+			sb.WriteByte('.')
+			sb.Write(digits[:at])
+			sb.WriteByte('(')
+			sb.Write(digits[at:])
+			sb.WriteByte(')')
+			return sb.String()
+		}
+		seen[key] = len(digits)
+
+		rem.Mul(rem, ten)
+		digit.QuoRem(rem, r.Denom(), rem)
+		digits = append(digits, byte('0'+digit.Int64()))
+	}
+
+	sb.WriteByte('.')
+	sb.Write(digits)
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package mathematigo
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		v        Value
+		expected string
+	}{
+		{nil, "null"},
+		{0.1, "0.1"},
+		{1e21, "1e+21"},
+		{math.Inf(-1), "-Infinity"},
+		{math.NaN(), "NaN"},
+		{true, "true"},
+		{`say "hi"`, `"say \"hi\""`},
+		{big.NewRat(-1, 3), "-1/3"},
+		{big.NewRat(4, 2), "2"},
+		{new(big.Float).SetPrec(bigPrecisionBits(5)).SetFloat64(2.5), "2.5"},
+		{complex(2, -3), "2 - 3i"},
+		{complex(0, 1), "i"},
+		{complex(0, -2.5), "-2.5i"},
+		{complex(1.5, 0), "1.5"},
+		{Function(nil), "function"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, Format(c.v), "%v", c.v)
+	}
+}
+
+func TestFormatFractionsAsDecimals(t *testing.T) {
+	cases := []struct {
+		r        *big.Rat
+		expected string
+	}{
+		{big.NewRat(1, 3), "0.(3)"},
+		{big.NewRat(-1, 6), "-0.1(6)"},
+		{big.NewRat(1, 7), "0.(142857)"},
+		{big.NewRat(5, 4), "1.25"},
+		{big.NewRat(7, 1), "7"},
+		{big.NewRat(355, 113), "3.1415929203539823008849557522123893805309734513274336283185840707"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, Format(c.r, FormatFractions(FractionDecimal)), c.r.String())
+	}
+}
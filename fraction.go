@@ -0,0 +1,251 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+var ErrDivisionByZero = errors.New("division by zero")
+
+// maxFractionPowBits bounds the size of exact Fraction powers. larger
+// results fall back to a float64
+const maxFractionPowBits = 1 << 16
+
+// parseRat reads a decimal literal exactly, e.g. 0.1 is 1/10
+func parseRat(text string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid Fraction %q", ErrInvalidSyntax, text)
+	}
+	return r, nil
+}
+
+// toRat converts a number to a Fraction. floats go through their shortest
+// decimal form, so 0.1 becomes 1/10. ok is false for NaN and Inf, which a
+// Fraction cannot hold
+func toRat(v Value) (*big.Rat, bool) {
+	switch x := v.(type) {
+	case *big.Rat:
+		return x, true
+	case *big.Int:
+		return new(big.Rat).SetInt(x), true
+	}
+
+	x, ok := toNumber(v)
+	if !ok || math.IsNaN(x) || math.IsInf(x, 0) {
+		return nil, false
+	}
+
+	r, err := parseRat(strconv.FormatFloat(x, 'g', -1, 64))
+	return r, err == nil
+}
+
+func ratInt(x int64) *big.Rat {
+	return new(big.Rat).SetInt64(x)
+}
+
+func ratTrunc(x *big.Rat) *big.Rat {
+	if x.IsInt() {
+		return x
+	}
+	return new(big.Rat).SetInt(new(big.Int).Quo(x.Num(), x.Denom()))
+}
+
+func ratFloor(x *big.Rat) *big.Rat {
+	out := ratTrunc(x)
+	if x.Sign() < 0 && !x.IsInt() {
+		out.Sub(out, ratInt(1))
+	}
+	return out
+}
+
+func ratCeil(x *big.Rat) *big.Rat {
+	out := ratFloor(new(big.Rat).Neg(x))
+	return out.Neg(out)
+}
+
+func ratQuo(x, y *big.Rat) (Value, error) {
+	if y.Sign() == 0 {
+		return nil, fmt.Errorf("%w: %s / 0", ErrDivisionByZero, x.RatString())
+	}
+	return new(big.Rat).Quo(x, y), nil
+}
+
+// ratMod is floorMod for Fractions
+func ratMod(x, y *big.Rat) (Value, error) {
+	if y.Sign() == 0 {
+		return x, nil
+	}
+	q := ratFloor(new(big.Rat).Quo(x, y))
+	return new(big.Rat).Sub(x, q.Mul(q, y)), nil
+}
+
+// ratPow is exact for integer exponents. other exponents generally give
+// irrational results, so like mathjs they fall back to a float64
+func ratPow(x, y *big.Rat) (Value, error) {
+	// -n overflows for MinInt64, which is far past the bound anyway
+	if y.IsInt() && y.Num().IsInt64() && y.Num().Int64() != math.MinInt64 {
+		n := y.Num().Int64()
+		// the denominator is at least 1, so size is never 0. the bound is
+		// checked by division, the product can overflow
+		size := int64(max(x.Num().BitLen(), x.Denom().BitLen()))
+		if max(n, -n) <= maxFractionPowBits/size {
+			return ratPowInt(x, n)
+		}
+	}
+
+	fx, _ := x.Float64()
+	fy, _ := y.Float64()
+	return floatPow(fx, fy), nil
+}
+
+func ratPowInt(x *big.Rat, n int64) (Value, error) {
+	e := big.NewInt(max(n, -n))
+	num := new(big.Int).Exp(x.Num(), e, nil)
+	den := new(big.Int).Exp(x.Denom(), e, nil)
+
+	if n < 0 {
+		if num.Sign() == 0 {
+			return nil, fmt.Errorf("%w: 0 ^ %d", ErrDivisionByZero, n)
+		}
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+func ratFactorial(x *big.Rat) (Value, error) {
+	if !x.IsInt() {
+		// the gamma function has no exact value
+		fx, _ := x.Float64()
+		return wrapFloat(factorialFloat(fx))
+	}
+
+	switch {
+	case x.Sign() < 0:
+		return nil, fmt.Errorf("%w: value must be non-negative in function %s", ErrInvalidOperand, OperatorFnFactorial)
+	case !x.Num().IsInt64() || x.Num().Int64() > maxBigFactorial:
+		return nil, fmt.Errorf("%w: value must be at most %d in function %s for Fractions", ErrInvalidOperand, maxBigFactorial, OperatorFnFactorial)
+	}
+
+	return new(big.Rat).SetInt(new(big.Int).MulRange(1, x.Num().Int64())), nil
+}
+
+func ratBitwise(fn OperatorFnName, x, y *big.Rat, op func(z, x, y *big.Int) *big.Int) (Value, error) {
+	if !x.IsInt() || !y.IsInt() {
+		return nil, integersExpectedErr(fn)
+	}
+	return new(big.Rat).SetInt(op(new(big.Int), x.Num(), y.Num())), nil
+}
+
+func ratShift(fn OperatorFnName, x, y *big.Rat, op func(z, x *big.Int, n uint) *big.Int) (Value, error) {
+	if !x.IsInt() || !y.IsInt() {
+		return nil, integersExpectedErr(fn)
+	}
+	n, err := shiftCount(fn, y.Num())
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(op(new(big.Int), x.Num(), n)), nil
+}
+
+// ratRound rounds half away from zero at n decimals
+func ratRound(x *big.Rat, n int) (*big.Rat, error) {
+	if err := checkDecimals("round", n); err != nil {
+		return nil, err
+	}
+	return roundRat(x, n), nil
+}
+
+// ratScaled applies fn at n decimals
+func ratScaled(name string, x *big.Rat, n int, fn func(*big.Rat) *big.Rat) (*big.Rat, error) {
+	if err := checkDecimals(name, n); err != nil {
+		return nil, err
+	}
+
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+	out := fn(new(big.Rat).Mul(x, scale))
+	return out.Quo(out, scale), nil
+}
+
+func ratGCD(a, b *big.Rat) (*big.Rat, error) {
+	if !a.IsInt() || !b.IsInt() {
+		return nil, fmt.Errorf("%w: integers expected in function gcd", ErrArgumentType)
+	}
+	i := new(big.Int).Abs(a.Num())
+	j := new(big.Int).Abs(b.Num())
+	return new(big.Rat).SetInt(new(big.Int).GCD(nil, nil, i, j)), nil
+}
+
+func ratLCM(a, b *big.Rat) (*big.Rat, error) {
+	if !a.IsInt() || !b.IsInt() {
+		return nil, fmt.Errorf("%w: integers expected in function lcm", ErrArgumentType)
+	}
+	if a.Sign() == 0 || b.Sign() == 0 {
+		return new(big.Rat), nil
+	}
+
+	i := new(big.Int).Abs(a.Num())
+	j := new(big.Int).Abs(b.Num())
+	g := new(big.Int).GCD(nil, nil, i, j)
+	return new(big.Rat).SetInt(i.Mul(i.Quo(i, g), j)), nil
+}
+
+func reduceRat(acc *big.Rat, rest []*big.Rat, fn func(a, b *big.Rat) (*big.Rat, error)) (*big.Rat, error) {
+	for _, x := range rest {
+		var err error
+		if acc, err = fn(acc, x); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+func ratMax(a, b *big.Rat) (*big.Rat, error) {
+	if b.Cmp(a) > 0 {
+		return b, nil
+	}
+	return a, nil
+}
+
+func ratMin(a, b *big.Rat) (*big.Rat, error) {
+	if b.Cmp(a) < 0 {
+		return b, nil
+	}
+	return a, nil
+}
+
+// registerFraction adds the Fraction overloads of the standard functions
+// that have exact results. the others convert Fractions to float64
+func registerFraction(r *Registry) {
+	r.MustRegister("abs", func(x *big.Rat) *big.Rat { return new(big.Rat).Abs(x) })
+	r.MustRegister("sign", func(x *big.Rat) *big.Rat { return ratInt(int64(x.Sign())) })
+	r.MustRegister("fix", ratTrunc)
+
+	r.MustRegister("round", func(x *big.Rat) *big.Rat { return roundRat(x, 0) })
+	r.MustRegister("round", ratRound)
+	r.MustRegister("floor", ratFloor)
+	r.MustRegister("floor", func(x *big.Rat, n int) (*big.Rat, error) { return ratScaled("floor", x, n, ratFloor) })
+	r.MustRegister("ceil", ratCeil)
+	r.MustRegister("ceil", func(x *big.Rat, n int) (*big.Rat, error) { return ratScaled("ceil", x, n, ratCeil) })
+
+	r.MustRegister("max", func(x *big.Rat, rest ...*big.Rat) (*big.Rat, error) { return reduceRat(x, rest, ratMax) })
+	r.MustRegister("min", func(x *big.Rat, rest ...*big.Rat) (*big.Rat, error) { return reduceRat(x, rest, ratMin) })
+
+	r.MustRegister("gcd", func(a, b *big.Rat, rest ...*big.Rat) (*big.Rat, error) {
+		g, err := ratGCD(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return reduceRat(g, rest, ratGCD)
+	})
+	r.MustRegister("lcm", func(a, b *big.Rat, rest ...*big.Rat) (*big.Rat, error) {
+		l, err := ratLCM(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return reduceRat(l, rest, ratLCM)
+	})
+}
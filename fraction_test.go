@@ -0,0 +1,139 @@
+package mathematigo
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evalFraction(t *testing.T, expr string, scope Scope) Value {
+	t.Helper()
+
+	node, err := Parse(expr, ParseNumbers(NumberFraction))
+	require.NoError(t, err)
+
+	v, err := Evaluate(node, scope)
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestParseFractions(t *testing.T) {
+	node, err := Parse("0.1 * 3", ParseNumbers(NumberFraction))
+	require.NoError(t, err)
+
+	expected := &OperatorNode{
+		Args: []MathNode{NewFractionNode("0.1"), NewFractionNode("3")},
+		Op:   "*",
+		Fn:   OperatorFnMultiply,
+	}
+	assert.True(t, expected.Equal(node), node.String())
+}
+
+func TestFractionArithmetic(t *testing.T) {
+	cases := map[string]string{
+		"1/3 + 1/6":     "1/2",
+		"0.1 + 0.2":     "3/10",
+		"1/3 - 1/2":     "-1/6",
+		"2/3 * 3/4":     "1/2",
+		"(1/3) / (2/9)": "3/2",
+		"(2/3) ^ 3":     "8/27",
+		"(2/3) ^ -2":    "9/4",
+		"7/2 % 1":       "1/2",
+		"-7 % 3":        "2",
+		"5 % 0":         "5",
+		"-(1/3)":        "-1/3",
+		"20!":           "2432902008176640000",
+		"6 | 3":         "7",
+		"1.25e2":        "125",
+		"max(1/3, 0.3)": "1/3",
+		"abs(-1/3)":     "1/3",
+		"floor(-7/2)":   "-4",
+		"ceil(7/2)":     "4",
+		"round(5/2)":    "3",
+		"round(2/3, 2)": "67/100",
+		"fix(-7/2)":     "-3",
+		"gcd(12, 18)":   "6",
+		"lcm(4, 6)":     "12",
+		"sign(-1/3)":    "-1",
+	}
+
+	for expr, expected := range cases {
+		v := evalFraction(t, expr, nil)
+		require.IsType(t, &big.Rat{}, v, expr)
+		assert.Equal(t, expected, Format(v), expr)
+	}
+}
+
+func TestFractionComparisons(t *testing.T) {
+	cases := map[string]bool{
+		"1/3 + 1/6 == 1/2":  true,
+		"0.1 + 0.2 == 0.3":  true,
+		"1/3 < 0.3333334":   true,
+		"1/3 > 0.333333333": true,
+		"1/3 == x":          false,
+		"1/2 == x":          true,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalFraction(t, expr, MapScope{"x": 0.5}), expr)
+	}
+}
+
+func TestFractionFallsBackToNumbers(t *testing.T) {
+	// irrational results and values a Fraction cannot hold are numbers
+	assert.InDelta(t, 1.4142135623730951, evalFraction(t, "2 ^ (1/2)", nil), 1e-15)
+	assert.InDelta(t, 1.4142135623730951, evalFraction(t, "sqrt(2)", nil), 1e-15)
+	assert.Equal(t, "7/2", Format(evalFraction(t, "x + 1/2", MapScope{"x": 3.0})))
+
+	// so are powers too large to compute exactly, whatever the exponent
+	assert.Equal(t, math.Inf(1), evalFraction(t, "3 ^ 4611686018427387904", nil))
+	assert.Equal(t, 0.0, evalFraction(t, "2 ^ (0-9223372036854775808)", nil))
+
+	v := evalFraction(t, "1/2 + x", MapScope{"x": math.Inf(1)})
+	assert.Equal(t, math.Inf(1), v)
+
+	// BigNumbers win over Fractions
+	v = evalFraction(t, "1/4 + x", MapScope{"x": big.NewFloat(1)})
+	require.IsType(t, &big.Float{}, v)
+	assert.Equal(t, "1.25", Format(v))
+}
+
+func TestEvaluateWithFractions(t *testing.T) {
+	node, err := Parse("1/3 + x")
+	require.NoError(t, err)
+
+	e := NewEvaluator(WithNumbers(NumberFraction))
+	v, err := e.Evaluate(node, MapScope{"x": 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, "5/6", Format(v))
+
+	p, err := e.Compile(node)
+	require.NoError(t, err)
+	v, err = p.Eval(MapScope{"x": 1.0})
+	require.NoError(t, err)
+	assert.Equal(t, "4/3", Format(v))
+}
+
+func TestFractionErrors(t *testing.T) {
+	cases := map[string]error{
+		"1/0":            ErrDivisionByZero,
+		"0 ^ -1":         ErrDivisionByZero,
+		"1/2 | 1":        ErrInvalidOperand,
+		"(-1)!":          ErrInvalidOperand,
+		"gcd(1/2, 2)":    ErrArgumentType,
+		"round(1/3, 16)": ErrArgumentValue,
+		"1/3 + \"a\"":    ErrInvalidOperand,
+	}
+
+	for expr, expected := range cases {
+		node, err := Parse(expr, ParseNumbers(NumberFraction))
+		require.NoError(t, err)
+
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, expected, expr)
+	}
+}
@@ -0,0 +1,210 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+var ErrArgumentValue = errors.New("invalid argument value")
+
+// standardRegistry backs evaluators that were not given WithRegistry
+var standardRegistry = NewStandardRegistry()
+
+// NewStandardRegistry returns a fresh Registry holding the default function
+// set. names, arities and edge cases follow mathjs
+func NewStandardRegistry() *Registry {
+	r := NewRegistry()
+
+	for _, fn := range []struct {
+		name string
+		fn   func(float64) float64
+	}{
+		{"abs", math.Abs},
+		{"cbrt", math.Cbrt},
+		{"exp", math.Exp},
+		{"sign", sign},
+		{"fix", math.Trunc},
+		{"log10", math.Log10},
+		{"log2", math.Log2},
+		{"sin", math.Sin},
+		{"cos", math.Cos},
+		{"tan", math.Tan},
+		{"asin", math.Asin},
+		{"acos", math.Acos},
+		{"atan", math.Atan},
+		{"sinh", math.Sinh},
+		{"cosh", math.Cosh},
+		{"tanh", math.Tanh},
+	} {
+		r.MustRegister(fn.name, fn.fn)
+	}
+
+	r.MustRegister("atan2", math.Atan2)
+
+	// negative numbers have complex square roots and logarithms
+	r.MustRegister("sqrt", sqrtReal)
+	r.MustRegister("log", logReal)
+	r.MustRegister("log", logBase)
+
+	r.MustRegister("round", func(x float64) float64 { return roundDecimal(x, 0) })
+	r.MustRegister("round", func(x float64, n int) (float64, error) {
+		if err := checkDecimals("round", n); err != nil {
+			return 0, err
+		}
+		return roundDecimal(x, n), nil
+	})
+	r.MustRegister("floor", floorNearly)
+	r.MustRegister("floor", func(x float64, n int) (float64, error) { return scaled("floor", x, n, floorNearly) })
+	r.MustRegister("ceil", ceilNearly)
+	r.MustRegister("ceil", func(x float64, n int) (float64, error) { return scaled("ceil", x, n, ceilNearly) })
+
+	r.MustRegister("max", func(x float64, rest ...float64) float64 { return reduce(x, rest, math.Max) })
+	r.MustRegister("min", func(x float64, rest ...float64) float64 { return reduce(x, rest, math.Min) })
+	r.MustRegister("hypot", func(x float64, rest ...float64) float64 {
+		return math.Sqrt(reduce(x*x, rest, func(acc, y float64) float64 { return acc + y*y }))
+	})
+
+	r.MustRegister("gcd", func(a, b int64, rest ...int64) int64 { return reduceInt(gcd(a, b), rest, gcd) })
+	r.MustRegister("lcm", func(a, b int64, rest ...int64) int64 { return reduceInt(lcm(a, b), rest, lcm) })
+
+	registerIntegers(r)
+	registerBigNumber(r)
+	registerFraction(r)
+	registerComplex(r)
+	registerUnits(r)
+	registerArrays(r)
+	registerStrings(r)
+
+	// every operator is also callable by its mathjs name, e.g. add(1, 2)
+	for fn, op := range unaryOperators {
+		r.MustRegister(string(fn), func(a Value) (Value, error) { return op(a) })
+	}
+	for fn, op := range binaryOperators {
+		r.MustRegister(string(fn), func(a, b Value) (Value, error) { return op(a, b) })
+	}
+
+	return r
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		// keeps NaN and -0
+		return x
+	}
+}
+
+func reduce(acc float64, rest []float64, fn func(float64, float64) float64) float64 {
+	for _, x := range rest {
+		acc = fn(acc, x)
+	}
+	return acc
+}
+
+func reduceInt(acc int64, rest []int64, fn func(int64, int64) int64) int64 {
+	for _, x := range rest {
+		acc = fn(acc, x)
+	}
+	return acc
+}
+
+// maxDecimals is the most decimals round, floor and ceil accept, as in mathjs
+const maxDecimals = 15
+
+func checkDecimals(name string, n int) error {
+	if n < 0 || n > maxDecimals {
+		return fmt.Errorf("%w: number of decimals in function %s must be in the range of 0-%d", ErrArgumentValue, name, maxDecimals)
+	}
+	return nil
+}
+
+// roundDecimal rounds half away from zero on the shortest decimal form of x,
+// which is what mathjs does, so round(1.005, 2) is 1.01 even though the
+// float64 closest to 1.005 is slightly below it
+func roundDecimal(x float64, n int) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return x
+	}
+
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(x, 'f', -1, 64))
+	if !ok {
+		return x
+	}
+
+	out, _ := roundRat(r, n).Float64()
+	if out == 0 && math.Signbit(x) {
+		return math.Copysign(0, -1)
+	}
+	return out
+}
+
+// roundRat rounds r half away from zero at n decimals
+func roundRat(r *big.Rat, n int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	r = new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	// truncate, then step away from zero when the remainder is at least a half
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if new(big.Int).Lsh(m.Abs(m), 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(r.Sign())))
+	}
+
+	return new(big.Rat).SetFrac(q, scale)
+}
+
+// scaled applies fn at n decimals
+func scaled(name string, x float64, n int, fn func(float64) float64) (float64, error) {
+	if err := checkDecimals(name, n); err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return fn(x), nil
+	}
+
+	pow := math.Pow10(n)
+	return fn(x*pow) / pow, nil
+}
+
+// floorNearly rounds values within epsilon of an integer to that integer
+// first, so floor(0.1 * 3 * 10) is 3 like in mathjs
+func floorNearly(x float64) float64 {
+	if r := math.Round(x); nearlyEqual(x, r) {
+		return r
+	}
+	return math.Floor(x)
+}
+
+func ceilNearly(x float64) float64 {
+	if r := math.Round(x); nearlyEqual(x, r) {
+		return r
+	}
+	return math.Ceil(x)
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func lcm(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	out := a / gcd(a, b) * b
+	if out < 0 {
+		return -out
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package mathematigo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardFunctions(t *testing.T) {
+	cases := map[string]Value{
+		"max(a, b)":           5.0,
+		"max(1, 7, 3)":        7.0,
+		"min(4, -2, 9)":       -2.0,
+		"sqrt(16)":            4.0,
+		"abs(-3.5)":           3.5,
+		"round(2.5)":          3.0,
+		"round(-2.5)":         -3.0,
+		"round(3.14159, 2)":   3.14,
+		"round(1.005, 2)":     1.01,
+		"floor(2.7)":          2.0,
+		"floor(-2.2)":         -3.0,
+		"floor(0.1 * 3 * 10)": 3.0,
+		"ceil(2.1)":           3.0,
+		"ceil(2.123, 1)":      2.2,
+		"log(e)":              1.0,
+		"log(8, 2)":           3.0,
+		"log10(1000)":         3.0,
+		"exp(0)":              1.0,
+		"sign(-4)":            -1.0,
+		"sign(0)":             0.0,
+		"hypot(3, 4)":         5.0,
+		"gcd(12, 18)":         6.0,
+		"gcd(-12, 18, 8)":     2.0,
+		"lcm(4, 6)":           12.0,
+		"lcm(0, 6)":           0.0,
+		"sin(0)":              0.0,
+		"cos(0)":              1.0,
+		"tan(0)":              0.0,
+		"add(1, 2)":           3.0,
+		"factorial(4)":        24.0,
+		"larger(2, 1)":        true,
+	}
+
+	scope := MapScope{"a": 5.0, "b": 3.0, "e": math.E}
+	for expr, expected := range cases {
+		v := evalString(t, expr, scope)
+		if f, ok := expected.(float64); ok {
+			assert.InDelta(t, f, v, 1e-12, expr)
+		} else {
+			assert.Equal(t, expected, v, expr)
+		}
+	}
+}
+
+func TestStandardFunctionEdgeCases(t *testing.T) {
+	assert.Equal(t, math.Inf(-1), evalString(t, "log(0)", nil))
+	// negative numbers have complex roots and logarithms
+	assert.Equal(t, complex(0, 2), evalString(t, "sqrt(-4)", nil))
+	assert.Equal(t, complex(0, math.Pi), evalString(t, "log(-1)", nil))
+}
+
+func TestStandardFunctionErrors(t *testing.T) {
+	cases := map[string]error{
+		"max()":            ErrArity,
+		"sqrt(1, 2)":       ErrArity,
+		`sqrt("a")`:        ErrArgumentType,
+		"gcd(1.5, 2)":      ErrArgumentType,
+		"round(1.234, 16)": ErrArgumentValue,
+		"round(1.234, -1)": ErrArgumentValue,
+	}
+
+	for expr, expected := range cases {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, expected, expr)
+	}
+}
+
+func TestStandardRegistryIsExtensible(t *testing.T) {
+	r := NewStandardRegistry()
+	require.NoError(t, r.Register("double", func(x float64) float64 { return x * 2 }))
+
+	node, err := Parse("double(max(1, 2))")
+	require.NoError(t, err)
+
+	v, err := NewEvaluator(WithRegistry(r)).Evaluate(node, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, v)
+
+	// the shared default registry is untouched
+	_, err = Evaluate(node, nil)
+	require.ErrorIs(t, err, ErrUndefinedFunction)
+}
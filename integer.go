@@ -0,0 +1,153 @@
+package mathematigo
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// maxIntPowBits bounds the size of exact integer powers. larger results fall
+// back to a float64
+const maxIntPowBits = 1 << 16
+
+func intArith(op func(z, x, y *big.Int) *big.Int) func(x, y *big.Int) (Value, error) {
+	return func(x, y *big.Int) (Value, error) {
+		return op(new(big.Int), x, y), nil
+	}
+}
+
+// intQuo is exact when y divides x. other quotients are not integers and
+// become a float64, so 7 / 2 is 3.5
+func intQuo(x, y *big.Int) (Value, error) {
+	if y.Sign() == 0 {
+		fx, _ := toNumber(x)
+		fy, _ := toNumber(y)
+		return fx / fy, nil
+	}
+
+	q, m := new(big.Int).QuoRem(x, y, new(big.Int))
+	if m.Sign() == 0 {
+		return q, nil
+	}
+	f, _ := new(big.Rat).SetFrac(x, y).Float64()
+	return f, nil
+}
+
+// intMod is floorMod for integers. big.Int's Mod is euclidean, so the
+// remainder is fixed up to take the sign of the divisor
+func intMod(x, y *big.Int) (Value, error) {
+	if y.Sign() == 0 {
+		return x, nil
+	}
+	m := new(big.Int).Rem(x, y)
+	if m.Sign() != 0 && m.Sign() != y.Sign() {
+		m.Add(m, y)
+	}
+	return m, nil
+}
+
+// intPow is exact for non-negative exponents. negative exponents give
+// fractions, which like too large results fall back to a float64
+func intPow(x, y *big.Int) (Value, error) {
+	if y.Sign() >= 0 && y.IsInt64() {
+		// 0, 1 and -1 stay small whatever the exponent. the bound is
+		// checked by division, the product can overflow
+		if x.BitLen() <= 1 || y.Int64() <= maxIntPowBits/int64(x.BitLen()) {
+			return new(big.Int).Exp(x, y, nil), nil
+		}
+	}
+
+	fx, _ := toNumber(x)
+	fy, _ := toNumber(y)
+	return floatPow(fx, fy), nil
+}
+
+func intFactorial(x *big.Int) (Value, error) {
+	if x.Sign() < 0 || x.Cmp(big.NewInt(maxBigFactorial)) > 0 {
+		// the float64 implementation reports negative values and gives Inf
+		// for the large ones
+		f, _ := toNumber(x)
+		return wrapFloat(factorialFloat(f))
+	}
+	return new(big.Int).MulRange(1, x.Int64()), nil
+}
+
+func intShift(fn OperatorFnName, op func(z, x *big.Int, n uint) *big.Int) func(x, y *big.Int) (Value, error) {
+	return func(x, y *big.Int) (Value, error) {
+		n, err := shiftCount(fn, y)
+		if err != nil {
+			return nil, err
+		}
+		return op(new(big.Int), x, n), nil
+	}
+}
+
+// intRightLogShift shifts zeros in from the left. for non-negative x that
+// is the arithmetic shift at any size, negative x must fit 64 bits to have a
+// width to shift into, like the numbers do
+func intRightLogShift(x, y *big.Int) (Value, error) {
+	n, err := shiftCount(OperatorFnRightLogShift, y)
+	if err != nil {
+		return nil, err
+	}
+	if x.Sign() >= 0 {
+		return new(big.Int).Rsh(x, n), nil
+	}
+	if !x.IsInt64() {
+		return nil, fmt.Errorf("%w: negative values in function %s must fit 64 bits", ErrArgumentValue, OperatorFnRightLogShift)
+	}
+	return big.NewInt(int64(uint64(x.Int64()) >> n)), nil
+}
+
+func reduceInts(acc *big.Int, rest []*big.Int, fn func(a, b *big.Int) *big.Int) *big.Int {
+	for _, x := range rest {
+		acc = fn(acc, x)
+	}
+	return acc
+}
+
+// intGCD is never negative, whatever the signs of a and b
+func intGCD(a, b *big.Int) *big.Int {
+	return new(big.Int).GCD(nil, nil, a, b)
+}
+
+func intLCM(a, b *big.Int) *big.Int {
+	if a.Sign() == 0 || b.Sign() == 0 {
+		return new(big.Int)
+	}
+	out := new(big.Int).Quo(a, intGCD(a, b))
+	out.Mul(out, b)
+	return out.Abs(out)
+}
+
+func intMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func intMin(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// registerIntegers adds the bigint overloads of the standard functions that
+// have integer results. the others convert their bigint arguments to
+// float64
+func registerIntegers(r *Registry) {
+	r.MustRegister("abs", func(x *big.Int) *big.Int { return new(big.Int).Abs(x) })
+	r.MustRegister("sign", func(x *big.Int) *big.Int { return big.NewInt(int64(x.Sign())) })
+
+	// integers are already rounded
+	for _, name := range []string{"fix", "round", "floor", "ceil"} {
+		r.MustRegister(name, func(x *big.Int) *big.Int { return x })
+	}
+
+	r.MustRegister("max", func(x *big.Int, rest ...*big.Int) *big.Int { return reduceInts(x, rest, intMax) })
+	r.MustRegister("min", func(x *big.Int, rest ...*big.Int) *big.Int { return reduceInts(x, rest, intMin) })
+
+	r.MustRegister("gcd", func(a, b *big.Int, rest ...*big.Int) *big.Int { return reduceInts(intGCD(a, b), rest, intGCD) })
+	r.MustRegister("lcm", func(a, b *big.Int, rest ...*big.Int) *big.Int { return reduceInts(intLCM(a, b), rest, intLCM) })
+}
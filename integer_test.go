@@ -0,0 +1,157 @@
+package mathematigo
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evalInt(t *testing.T, expr string, scope Scope) Value {
+	t.Helper()
+
+	node, err := Parse(expr, ParseNumbers(NumberInt))
+	require.NoError(t, err, expr)
+
+	v, err := NewEvaluator(WithNumbers(NumberInt)).Evaluate(node, scope)
+	require.NoError(t, err, expr)
+
+	return v
+}
+
+func TestParseIntegers(t *testing.T) {
+	node, err := Parse("7 % 3 + 0.5", ParseNumbers(NumberInt))
+	require.NoError(t, err)
+	assert.Equal(t, "7 % 3 + 0.5", node.String())
+
+	op := node.(*OperatorNode)
+	assert.Equal(t, NewIntNode(7), op.Args[0].(*OperatorNode).Args[0])
+	assert.IsType(t, new(FloatNode), op.Args[1])
+
+	// past int64 the literal keeps its digits in a big.Int
+	node, err = Parse("123456789012345678901234567890", ParseNumbers(NumberInt))
+	require.NoError(t, err)
+	require.IsType(t, &BigIntNode{}, node)
+	assert.Equal(t, "123456789012345678901234567890", node.(*BigIntNode).BigInt().String())
+	assert.Equal(t, "123456789012345678901234567890", node.String())
+
+	// exponents keep the literal a float
+	node, err = Parse("1e3", ParseNumbers(NumberInt))
+	require.NoError(t, err)
+	assert.IsType(t, new(FloatNode), node)
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	cases := map[string]string{
+		"7 % 3":                      "1",
+		"-7 % 3":                     "2",
+		"7 % -3":                     "-2",
+		"5 % 0":                      "5",
+		"2^62":                       "4611686018427387904",
+		"2^64 + 1":                   "18446744073709551617",
+		"20!":                        "2432902008176640000",
+		"25!":                        "15511210043330985984000000",
+		"9007199254740993 + 0":       "9007199254740993",
+		"9007199254740993 - 1":       "9007199254740992",
+		"3 * 4":                      "12",
+		"12 / 4":                     "3",
+		"-5":                         "-5",
+		"6 | 3":                      "7",
+		"6 & 3":                      "2",
+		"6 ^| 3":                     "5",
+		"~5":                         "-6",
+		"1 << 70":                    "1180591620717411303424",
+		"-8 >> 1":                    "-4",
+		"-8 >>> 1":                   "9223372036854775804",
+		"-1 >>> 60":                  "15",
+		"(1 << 70) >>> 68":           "4",
+		"0xFFFFFFFFFFFFFFFF":         "18446744073709551615",
+		"abs(-9007199254740993)":     "9007199254740993",
+		"max(3, 9007199254740993)":   "9007199254740993",
+		"gcd(12, 18)":                "6",
+		"lcm(4, 6)":                  "12",
+		"sign(-3)":                   "-1",
+		"round(7)":                   "7",
+		"1e3 + 1":                    "1001",
+		"sum(1:3)":                   "6",
+		"[1, 2, 3][2]":               "2",
+		"x = 9007199254740993; x":    "9007199254740993",
+		"true ? 18014398509481985:0": "18014398509481985",
+	}
+
+	for expr, expected := range cases {
+		v := evalInt(t, expr, nil)
+		require.IsType(t, &big.Int{}, v, expr)
+		assert.Equal(t, expected, Format(v), expr)
+	}
+}
+
+func TestIntegerFallsBackToFloat(t *testing.T) {
+	cases := map[string]float64{
+		"7 / 2":        3.5,
+		"1 / 0":        math.Inf(1),
+		"2 ^ -1":       0.5,
+		"7 + 0.5":      7.5,
+		"sqrt(16)":     4,
+		"(2 ^ 4) / 32": 0.5,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalInt(t, expr, nil), expr)
+	}
+
+	// results past the size limits are floats as well
+	assert.Equal(t, math.Inf(1), evalInt(t, "100001!", nil))
+	assert.IsType(t, 0.0, evalInt(t, "3 ^ 100000", nil))
+	assert.Equal(t, math.Inf(1), evalInt(t, "3 ^ 4611686018427387904", nil))
+	assert.Equal(t, "1", Format(evalInt(t, "1 ^ 4611686018427387904", nil)))
+}
+
+func TestIntegerComparisons(t *testing.T) {
+	assert.Equal(t, true, evalInt(t, "9007199254740993 > 9007199254740992", nil))
+	assert.Equal(t, false, evalInt(t, "9007199254740993 == 9007199254740992", nil))
+	assert.Equal(t, true, evalInt(t, "3 == 3.0", nil))
+	assert.Equal(t, true, evalInt(t, "2 < 2.5", nil))
+	assert.Equal(t, "bigint", typeOf(evalInt(t, "1", nil)))
+
+	_, err := evalIntErr("(-1)!")
+	require.ErrorIs(t, err, ErrInvalidOperand)
+	_, err = evalIntErr("1 << -1")
+	require.ErrorIs(t, err, ErrArgumentValue)
+	_, err = evalIntErr("-(1 << 70) >>> 1")
+	require.ErrorIs(t, err, ErrArgumentValue)
+}
+
+func TestIntegerScope(t *testing.T) {
+	id, ok := new(big.Int).SetString("9007199254740993", 10)
+	require.True(t, ok)
+
+	assert.Equal(t, "9007199254740994", Format(evalInt(t, "id + 1", MapScope{"id": id})))
+
+	// struct fields past 2^53 read as bigints
+	type rule struct{ ID int64 }
+	scope, err := NewStructScope(&rule{ID: 1<<53 + 1})
+	require.NoError(t, err)
+	v, ok := scope.Get("ID")
+	require.True(t, ok)
+	assert.Equal(t, id, v)
+
+	// bigints convert to integer parameters and back exactly
+	r := NewRegistry()
+	r.MustRegister("next", func(x int64) int64 { return x + 1 })
+	node, err := Parse("next(id)")
+	require.NoError(t, err)
+	v, err = NewEvaluator(WithRegistry(r)).Evaluate(node, MapScope{"id": id})
+	require.NoError(t, err)
+	assert.Equal(t, "9007199254740994", Format(v))
+}
+
+func evalIntErr(expr string) (Value, error) {
+	node, err := Parse(expr, ParseNumbers(NumberInt))
+	if err != nil {
+		return nil, err
+	}
+	return NewEvaluator(WithNumbers(NumberInt)).Evaluate(node, nil)
+}
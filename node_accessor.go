@@ -0,0 +1,45 @@
+package mathematigo
+
+// AccessorNode reads a property or an element of an object, array or string,
+// e.g. order.customer, items[1] or m[2, 3]. indices start at 1 like in mathjs
+type AccessorNode struct {
+	Object MathNode   // not nil
+	Index  *IndexNode // not nil
+}
+
+func NewAccessorNode(object MathNode, index *IndexNode) *AccessorNode {
+	return &AccessorNode{Object: object, Index: index}
+}
+
+func (a *AccessorNode) String() string {
+	return a.Object.String() + a.Index.String()
+}
+
+func (a *AccessorNode) ForEach(cb func(MathNode)) {
+	cb(a)
+	a.Object.ForEach(cb)
+	a.Index.ForEach(cb)
+}
+
+func (a *AccessorNode) Equal(other MathNode) bool {
+	otherAccessor, ok := other.(*AccessorNode)
+	if !ok {
+		return false
+	}
+	return a.Object.Equal(otherAccessor.Object) && a.Index.Equal(otherAccessor.Index)
+}
+
+func (a *AccessorNode) Transform(fn func(MathNode) MathNode) MathNode {
+	res := fn(a)
+	if res != a {
+		return res
+	}
+	a.Object = a.Object.Transform(fn)
+	// the index stays an IndexNode, a transform that replaces it is ignored
+	if index, ok := a.Index.Transform(fn).(*IndexNode); ok {
+		a.Index = index
+	}
+	return a
+}
+
+var _ MathNode = (*AccessorNode)(nil)
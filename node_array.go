@@ -0,0 +1,59 @@
+package mathematigo
+
+import "strings"
+
+// ArrayNode is an array literal, e.g. [1, 2, 3]. the rows of a matrix
+// written as [1, 2; 3, 4] are ArrayNodes themselves
+type ArrayNode struct {
+	Items []MathNode
+}
+
+func NewArrayNode(items ...MathNode) *ArrayNode { return &ArrayNode{Items: items} }
+
+func (a *ArrayNode) String() string {
+	parts := make([]string, 0, len(a.Items))
+	for _, item := range a.Items {
+		parts = append(parts, item.String())
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func (a *ArrayNode) ForEach(cb func(MathNode)) {
+	cb(a)
+
+	for _, item := range a.Items {
+		item.ForEach(cb) // recursively traverse children
+	}
+}
+
+func (a *ArrayNode) Equal(other MathNode) bool {
+	otherArray, ok := other.(*ArrayNode)
+	if !ok {
+		return false
+	}
+
+	if len(a.Items) != len(otherArray.Items) {
+		return false
+	}
+
+	for i := range a.Items {
+		if !a.Items[i].Equal(otherArray.Items[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a *ArrayNode) Transform(fn func(MathNode) MathNode) MathNode {
+	res := fn(a)
+	if res != a {
+		return res
+	}
+	for i, item := range a.Items {
+		a.Items[i] = item.Transform(fn)
+	}
+	return a
+}
+
+var _ MathNode = (*ArrayNode)(nil)
@@ -0,0 +1,47 @@
+package mathematigo
+
+import "fmt"
+
+// AssignmentNode stores the value of an expression in the scope, e.g. x = 5.
+// it evaluates to the assigned value
+type AssignmentNode struct {
+	Object *SymbolNode // not nil
+	Value  MathNode    // not nil
+}
+
+func NewAssignmentNode(object *SymbolNode, value MathNode) *AssignmentNode {
+	return &AssignmentNode{Object: object, Value: value}
+}
+
+func (a *AssignmentNode) String() string {
+	return fmt.Sprintf("%s = %s", a.Object.String(), a.Value.String())
+}
+
+func (a *AssignmentNode) ForEach(cb func(MathNode)) {
+	cb(a)
+	a.Object.ForEach(cb)
+	a.Value.ForEach(cb)
+}
+
+func (a *AssignmentNode) Equal(other MathNode) bool {
+	otherAssign, ok := other.(*AssignmentNode)
+	if !ok {
+		return false
+	}
+	return a.Object.Equal(otherAssign.Object) && a.Value.Equal(otherAssign.Value)
+}
+
+func (a *AssignmentNode) Transform(fn func(MathNode) MathNode) MathNode {
+	res := fn(a)
+	if res != a {
+		return res
+	}
+	// the target stays a symbol, a transform that replaces it is ignored
+	if sym, ok := a.Object.Transform(fn).(*SymbolNode); ok {
+		a.Object = sym
+	}
+	a.Value = a.Value.Transform(fn)
+	return a
+}
+
+var _ MathNode = (*AssignmentNode)(nil)
@@ -0,0 +1,151 @@
+package mathematigo
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// NewBigIntNode is a decimal literal of n, which may be of any size
+func NewBigIntNode(n *big.Int) *BigIntNode {
+	return &BigIntNode{value: new(big.Int).Set(n), radix: 10}
+}
+
+// BigIntNode is an integer literal that an IntNode cannot hold. the parser
+// produces them for 0b, 0o and 0x literals, which keep their radix and word
+// size so String gives back what was written, and with
+// ParseNumbers(NumberInt) for decimal literals past int64
+type BigIntNode struct {
+	value *big.Int
+	// radix is 2, 8, 10 or 16
+	radix int
+	// wordSize is the number of bits of a word size suffix such as i8, which
+	// makes the literal a two's complement. 0 when there is none
+	wordSize int
+}
+
+var radixPrefixes = map[int]string{2: "0b", 8: "0o", 16: "0x"}
+
+// BigInt is the value of the literal. it is a copy, changing it does not
+// change the node
+func (b *BigIntNode) BigInt() *big.Int {
+	return new(big.Int).Set(b.value)
+}
+
+// Int64 is the value of the literal, ok is false when it does not fit in an
+// int64
+func (b *BigIntNode) Int64() (v int64, ok bool) {
+	return b.value.Int64(), b.value.IsInt64()
+}
+
+// Radix is 2, 8, 10 or 16, the base the literal was written in
+func (b *BigIntNode) Radix() int { return b.radix }
+
+// WordSize is the number of bits of a word size suffix such as i8, 0 when
+// there is none
+func (b *BigIntNode) WordSize() int { return b.wordSize }
+
+func (b *BigIntNode) String() string {
+	prefix, ok := radixPrefixes[b.radix]
+	if !ok {
+		return b.value.String()
+	}
+
+	// with a word size the digits are those of the two's complement
+	digits := new(big.Int).Abs(b.value)
+	sign := ""
+	switch {
+	case b.wordSize > 0:
+		digits.SetUint64(uint64(b.value.Int64()))
+		if b.wordSize < 64 {
+			digits.SetUint64(digits.Uint64() & (1<<b.wordSize - 1))
+		}
+	case b.value.Sign() < 0:
+		sign = "-"
+	}
+
+	out := sign + prefix + strings.ToUpper(digits.Text(b.radix))
+	if b.wordSize > 0 {
+		out += "i" + strconv.Itoa(b.wordSize)
+	}
+	return out
+}
+
+func (b *BigIntNode) ForEach(cb func(MathNode)) {
+	cb(b)
+}
+
+// Equal compares values, 0x10 and 16 are equal whether 16 is a BigIntNode
+// or an IntNode
+func (b *BigIntNode) Equal(other MathNode) bool {
+	switch o := other.(type) {
+	case *BigIntNode:
+		return b.value.Cmp(o.value) == 0
+	case *IntNode:
+		return b.value.IsInt64() && b.value.Int64() == int64(*o)
+	}
+	return false
+}
+
+func (b *BigIntNode) Transform(fn func(MathNode) MathNode) MathNode { return fn(b) }
+
+var _ MathNode = (*BigIntNode)(nil)
+
+// parseRadixInt parses a 0b, 0o or 0x literal with an optional word size
+// suffix. like in mathjs, 0xFFi8 is the 8 bit two's complement -1
+func parseRadixInt(text string) (*BigIntNode, error) {
+	radix := 0
+	for r, prefix := range radixPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			radix = r
+		}
+	}
+	if radix == 0 {
+		return nil, fmt.Errorf("%w: %s is not a binary, octal or hexadecimal literal", ErrInvalidSyntax, text)
+	}
+
+	digits, suffix, hasSuffix := strings.Cut(text[2:], "i")
+	if strings.Contains(digits, ".") {
+		return nil, fmt.Errorf("%w: %s, binary, octal and hexadecimal literals must be integers", ErrInvalidSyntax, text)
+	}
+
+	n, ok := new(big.Int).SetString(digits, radix)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid integer %s", ErrInvalidSyntax, text)
+	}
+
+	if !hasSuffix {
+		return &BigIntNode{value: n, radix: radix}, nil
+	}
+
+	size, err := strconv.Atoi(suffix)
+	if err != nil || size < 1 || size > 64 {
+		return nil, fmt.Errorf("%w: word size of %s must be in the range of 1-64", ErrInvalidSyntax, text)
+	}
+	if n.BitLen() > size {
+		return nil, fmt.Errorf("%w: %s does not fit in %d bits", ErrInvalidSyntax, text, size)
+	}
+
+	// reinterpret the top bit as the sign
+	u := n.Uint64()
+	v := int64(u)
+	if size < 64 && u >= 1<<(size-1) {
+		v -= 1 << size
+	}
+	return &BigIntNode{value: big.NewInt(v), radix: radix, wordSize: size}, nil
+}
+
+// parseDecimalInt reads an integral decimal literal into an IntNode, or a
+// BigIntNode past int64. ok is false for literals with a decimal point or an
+// exponent, which stay FloatNodes
+func parseDecimalInt(text string) (MathNode, bool) {
+	n, ok := new(big.Int).SetString(text, 10)
+	switch {
+	case !ok:
+		return nil, false
+	case n.IsInt64():
+		return NewIntNode(n.Int64()), true
+	}
+	return &BigIntNode{value: n, radix: 10}, true
+}
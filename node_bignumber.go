@@ -0,0 +1,28 @@
+package mathematigo
+
+// BigNumberNode is a number literal kept as its decimal text, so no
+// precision is lost before evaluation. the parser produces it when numbers
+// are parsed with ParseNumbers(NumberBigNumber)
+type BigNumberNode string
+
+func NewBigNumberNode(value string) *BigNumberNode {
+	b := BigNumberNode(value)
+	return &b
+}
+
+func (b *BigNumberNode) String() string {
+	return string(*b)
+}
+
+func (b *BigNumberNode) ForEach(cb func(MathNode)) {
+	cb(b)
+}
+
+func (b *BigNumberNode) Equal(other MathNode) bool {
+	otherBig, ok := other.(*BigNumberNode)
+	return ok && *b == *otherBig
+}
+
+func (b *BigNumberNode) Transform(f func(MathNode) MathNode) MathNode { return f(b) }
+
+var _ MathNode = (*BigNumberNode)(nil)
@@ -2,15 +2,45 @@ package mathematigo
 
 import "strings"
 
+// BlockNode is a list of statements separated by newlines or semicolons.
+// mathjs evaluates a block to a ResultSet of every visible result, here it
+// evaluates to the result of the last visible statement instead, or to null
+// when every statement is hidden. Evaluate, Program and the VM agree on this
 type BlockNode struct {
 	Blocks []MathNode
+	// Hidden marks the statements that ended in a semicolon, their results
+	// are left out when the block is evaluated. nil when every statement is
+	// visible, otherwise as long as Blocks
+	Hidden []bool
+	// Comments holds the "#" comments of each statement, one per line, by
+	// the statement's index like Hidden. with ParseComments it is as long
+	// as Blocks, nil otherwise. the statement nodes themselves do not carry
+	// comments, and comments do not take part in Equal or String
+	Comments []string
+}
+
+// Visible reports whether the result of statement i is shown
+func (b *BlockNode) Visible(i int) bool {
+	return i >= len(b.Hidden) || !b.Hidden[i]
+}
+
+// Comment returns the comments attached to statement i, "" if there are none
+func (b *BlockNode) Comment(i int) string {
+	if i >= len(b.Comments) {
+		return ""
+	}
+	return b.Comments[i]
 }
 
 func (b *BlockNode) String() string {
 	parts := make([]string, 0, len(b.Blocks))
 
-	for _, x := range b.Blocks {
-		parts = append(parts, x.String())
+	for i, x := range b.Blocks {
+		if b.Visible(i) {
+			parts = append(parts, x.String())
+		} else {
+			parts = append(parts, x.String()+";")
+		}
 	}
 
 	return strings.Join(parts, "\n")
@@ -35,7 +65,7 @@ func (b *BlockNode) Equal(other MathNode) bool {
 	}
 
 	for i := range b.Blocks {
-		if !b.Blocks[i].Equal(otherBlock.Blocks[i]) {
+		if b.Visible(i) != otherBlock.Visible(i) || !b.Blocks[i].Equal(otherBlock.Blocks[i]) {
 			return false
 		}
 	}
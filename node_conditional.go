@@ -0,0 +1,47 @@
+package mathematigo
+
+import "fmt"
+
+// ConditionalNode is the ternary operator, condition ? trueExpr : falseExpr.
+// only the branch that is chosen is evaluated
+type ConditionalNode struct {
+	Condition MathNode // not nil
+	TrueExpr  MathNode // not nil
+	FalseExpr MathNode // not nil
+}
+
+func NewConditionalNode(condition, trueExpr, falseExpr MathNode) *ConditionalNode {
+	return &ConditionalNode{Condition: condition, TrueExpr: trueExpr, FalseExpr: falseExpr}
+}
+
+func (c *ConditionalNode) String() string {
+	return fmt.Sprintf("%s ? %s : %s", c.Condition.String(), c.TrueExpr.String(), c.FalseExpr.String())
+}
+
+func (c *ConditionalNode) ForEach(cb func(MathNode)) {
+	cb(c)
+	c.Condition.ForEach(cb)
+	c.TrueExpr.ForEach(cb)
+	c.FalseExpr.ForEach(cb)
+}
+
+func (c *ConditionalNode) Equal(other MathNode) bool {
+	otherCond, ok := other.(*ConditionalNode)
+	if !ok {
+		return false
+	}
+	return c.Condition.Equal(otherCond.Condition) && c.TrueExpr.Equal(otherCond.TrueExpr) && c.FalseExpr.Equal(otherCond.FalseExpr)
+}
+
+func (c *ConditionalNode) Transform(fn func(MathNode) MathNode) MathNode {
+	res := fn(c)
+	if res != c {
+		return res
+	}
+	c.Condition = c.Condition.Transform(fn)
+	c.TrueExpr = c.TrueExpr.Transform(fn)
+	c.FalseExpr = c.FalseExpr.Transform(fn)
+	return c
+}
+
+var _ MathNode = (*ConditionalNode)(nil)
@@ -1,5 +1,12 @@
 package mathematigo
 
+import (
+	"fmt"
+	"strings"
+)
+
+// ConstantNode is a string literal. it holds the decoded value, escapes
+// like \n are resolved by the scanner
 type ConstantNode string
 
 func NewConstantNode(value string) *ConstantNode {
@@ -7,8 +14,9 @@ func NewConstantNode(value string) *ConstantNode {
 	return &c
 }
 
+// String quotes the value in double quotes and escapes it again
 func (c *ConstantNode) String() string {
-	return `"` + string(*c) + `"`
+	return quoteString(string(*c))
 }
 
 func (c *ConstantNode) ForEach(cb func(MathNode)) {
@@ -23,3 +31,32 @@ func (c *ConstantNode) Equal(other MathNode) bool {
 func (c *ConstantNode) Transform(f func(MathNode) MathNode) MathNode { return f(c) }
 
 var _ MathNode = (*ConstantNode)(nil)
+
+var quoteEscapes = map[rune]string{
+	'"':  `\"`,
+	'\\': `\\`,
+	'\b': `\b`,
+	'\f': `\f`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+}
+
+// quoteString is JSON.stringify for strings: quotes, backslashes and control
+// characters are escaped, everything else is kept as it is
+func quoteString(v string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range v {
+		switch esc, ok := quoteEscapes[r]; {
+		case ok:
+			b.WriteString(esc)
+		case r < 0x20:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
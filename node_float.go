@@ -47,8 +47,7 @@ func (f *FloatNode) AsInt() (int64, bool) {
 // Returns the IntNode and true if successful, FloatNode and false otherwise
 func (f *FloatNode) ToIntNode() (*IntNode, bool) {
 	if intVal, ok := f.AsInt(); ok {
-		out := IntNode(intVal)
-		return &out, true
+		return NewIntNode(intVal), true
 	}
 	return nil, false
 }
@@ -0,0 +1,28 @@
+package mathematigo
+
+// FractionNode is a number literal kept as its decimal text, which converts
+// to a Fraction exactly. the parser produces it when numbers are parsed with
+// ParseNumbers(NumberFraction)
+type FractionNode string
+
+func NewFractionNode(value string) *FractionNode {
+	f := FractionNode(value)
+	return &f
+}
+
+func (f *FractionNode) String() string {
+	return string(*f)
+}
+
+func (f *FractionNode) ForEach(cb func(MathNode)) {
+	cb(f)
+}
+
+func (f *FractionNode) Equal(other MathNode) bool {
+	otherFraction, ok := other.(*FractionNode)
+	return ok && *f == *otherFraction
+}
+
+func (f *FractionNode) Transform(fn func(MathNode) MathNode) MathNode { return fn(f) }
+
+var _ MathNode = (*FractionNode)(nil)
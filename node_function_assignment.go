@@ -0,0 +1,57 @@
+package mathematigo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FunctionAssignmentNode defines a function, e.g. area(w, h) = w * h. it
+// evaluates to the Function, which is also stored in the scope under Name
+type FunctionAssignmentNode struct {
+	Name   string
+	Params []string
+	Expr   MathNode // not nil
+}
+
+func NewFunctionAssignmentNode(name string, params []string, expr MathNode) *FunctionAssignmentNode {
+	return &FunctionAssignmentNode{Name: name, Params: params, Expr: expr}
+}
+
+func (f *FunctionAssignmentNode) String() string {
+	return fmt.Sprintf("%s(%s) = %s", f.Name, strings.Join(f.Params, ", "), f.Expr.String())
+}
+
+func (f *FunctionAssignmentNode) ForEach(cb func(MathNode)) {
+	cb(f)
+	f.Expr.ForEach(cb)
+}
+
+func (f *FunctionAssignmentNode) Equal(other MathNode) bool {
+	otherFn, ok := other.(*FunctionAssignmentNode)
+	if !ok {
+		return false
+	}
+
+	if f.Name != otherFn.Name || len(f.Params) != len(otherFn.Params) {
+		return false
+	}
+
+	for i := range f.Params {
+		if f.Params[i] != otherFn.Params[i] {
+			return false
+		}
+	}
+
+	return f.Expr.Equal(otherFn.Expr)
+}
+
+func (f *FunctionAssignmentNode) Transform(fn func(MathNode) MathNode) MathNode {
+	res := fn(f)
+	if res != f {
+		return res
+	}
+	f.Expr = f.Expr.Transform(fn)
+	return f
+}
+
+var _ MathNode = (*FunctionAssignmentNode)(nil)
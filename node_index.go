@@ -0,0 +1,84 @@
+package mathematigo
+
+import "strings"
+
+// IndexNode holds the dimensions of an AccessorNode, e.g. the 2, 3 of
+// m[2, 3]. property access such as order.customer is an IndexNode with a
+// single ConstantNode dimension and DotNotation set
+type IndexNode struct {
+	Dimensions  []MathNode
+	DotNotation bool
+}
+
+func NewIndexNode(dimensions ...MathNode) *IndexNode {
+	return &IndexNode{Dimensions: dimensions}
+}
+
+// NewPropertyIndexNode is the index of the dot notation .name
+func NewPropertyIndexNode(name string) *IndexNode {
+	return &IndexNode{Dimensions: []MathNode{NewConstantNode(name)}, DotNotation: true}
+}
+
+func (i *IndexNode) String() string {
+	if name, ok := i.property(); ok {
+		return "." + name
+	}
+
+	parts := make([]string, 0, len(i.Dimensions))
+	for _, d := range i.Dimensions {
+		parts = append(parts, d.String())
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// property is the name of a dot notation index
+func (i *IndexNode) property() (string, bool) {
+	if !i.DotNotation || len(i.Dimensions) != 1 {
+		return "", false
+	}
+	c, ok := i.Dimensions[0].(*ConstantNode)
+	if !ok {
+		return "", false
+	}
+	return string(*c), true
+}
+
+func (i *IndexNode) ForEach(cb func(MathNode)) {
+	cb(i)
+
+	for _, d := range i.Dimensions {
+		d.ForEach(cb) // recursively traverse children
+	}
+}
+
+func (i *IndexNode) Equal(other MathNode) bool {
+	otherIndex, ok := other.(*IndexNode)
+	if !ok {
+		return false
+	}
+
+	if i.DotNotation != otherIndex.DotNotation || len(i.Dimensions) != len(otherIndex.Dimensions) {
+		return false
+	}
+
+	for j := range i.Dimensions {
+		if !i.Dimensions[j].Equal(otherIndex.Dimensions[j]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (i *IndexNode) Transform(fn func(MathNode) MathNode) MathNode {
+	res := fn(i)
+	if res != i {
+		return res
+	}
+	for j, d := range i.Dimensions {
+		i.Dimensions[j] = d.Transform(fn)
+	}
+	return i
+}
+
+var _ MathNode = (*IndexNode)(nil)
@@ -4,6 +4,9 @@ import "strconv"
 
 func NewIntNode(v int64) *IntNode { i := IntNode(v); return &i }
 
+// IntNode is a decimal integer literal that fits in an int64. the parser
+// produces them with ParseNumbers(NumberInt), literals written in another
+// radix or past int64 are BigIntNodes
 type IntNode int64
 
 func (i *IntNode) String() string {
@@ -14,7 +17,12 @@ func (i *IntNode) ForEach(cb func(MathNode)) {
 	cb(i)
 }
 
+// Equal compares values, so it is true for a BigIntNode of the same value
+// such as 0x10 and 16
 func (i *IntNode) Equal(other MathNode) bool {
+	if otherBig, ok := other.(*BigIntNode); ok {
+		return otherBig.Equal(i)
+	}
 	otherInt, ok := other.(*IntNode)
 	return ok && *i == *otherInt
 }
@@ -0,0 +1,70 @@
+package mathematigo
+
+import "strings"
+
+// ObjectProperty is one key and value of an ObjectNode
+type ObjectProperty struct {
+	Key   string
+	Value MathNode // not nil
+}
+
+// ObjectNode is an object literal, e.g. {name: "a", max: 10}. it keeps its
+// properties in the order they were written and evaluates to a
+// map[string]Value
+type ObjectNode struct {
+	Properties []ObjectProperty
+}
+
+func NewObjectNode(properties ...ObjectProperty) *ObjectNode {
+	return &ObjectNode{Properties: properties}
+}
+
+// String quotes every key, so the output parses back to an equal tree
+func (o *ObjectNode) String() string {
+	parts := make([]string, 0, len(o.Properties))
+	for _, p := range o.Properties {
+		parts = append(parts, NewConstantNode(p.Key).String()+": "+p.Value.String())
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (o *ObjectNode) ForEach(cb func(MathNode)) {
+	cb(o)
+
+	for _, p := range o.Properties {
+		p.Value.ForEach(cb) // recursively traverse children
+	}
+}
+
+func (o *ObjectNode) Equal(other MathNode) bool {
+	otherObject, ok := other.(*ObjectNode)
+	if !ok {
+		return false
+	}
+
+	if len(o.Properties) != len(otherObject.Properties) {
+		return false
+	}
+
+	for i, p := range o.Properties {
+		q := otherObject.Properties[i]
+		if p.Key != q.Key || !p.Value.Equal(q.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (o *ObjectNode) Transform(fn func(MathNode) MathNode) MathNode {
+	res := fn(o)
+	if res != o {
+		return res
+	}
+	for i, p := range o.Properties {
+		o.Properties[i].Value = p.Value.Transform(fn)
+	}
+	return o
+}
+
+var _ MathNode = (*ObjectNode)(nil)
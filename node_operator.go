@@ -21,6 +21,17 @@ const (
 	OperatorFnUnaryMinus OperatorFnName = "unaryMinus"
 	OperatorFnMod        OperatorFnName = "mod"
 	OperatorFnPower      OperatorFnName = "pow"
+	OperatorFnTo         OperatorFnName = "to"
+	OperatorFnAnd        OperatorFnName = "and"
+	OperatorFnOr         OperatorFnName = "or"
+	OperatorFnXor        OperatorFnName = "xor"
+	OperatorFnNot        OperatorFnName = "not"
+
+	OperatorFnBitXor          OperatorFnName = "bitXor"
+	OperatorFnBitNot          OperatorFnName = "bitNot"
+	OperatorFnLeftShift       OperatorFnName = "leftShift"
+	OperatorFnRightArithShift OperatorFnName = "rightArithShift"
+	OperatorFnRightLogShift   OperatorFnName = "rightLogShift"
 )
 
 var operatorFnsMap = map[OperatorFnName]struct{}{
@@ -40,6 +51,17 @@ var operatorFnsMap = map[OperatorFnName]struct{}{
 	OperatorFnUnaryMinus: {},
 	OperatorFnMod:        {},
 	OperatorFnPower:      {},
+	OperatorFnTo:         {},
+	OperatorFnAnd:        {},
+	OperatorFnOr:         {},
+	OperatorFnXor:        {},
+	OperatorFnNot:        {},
+
+	OperatorFnBitXor:          {},
+	OperatorFnBitNot:          {},
+	OperatorFnLeftShift:       {},
+	OperatorFnRightArithShift: {},
+	OperatorFnRightLogShift:   {},
 }
 
 func (o OperatorFnName) Valid() bool { // keep value receiver (tiny type)
@@ -59,6 +81,8 @@ func (o *OperatorNode) String() string {
 		switch o.Op {
 		case "!":
 			return fmt.Sprintf("%s%s", o.Args[0].String(), o.Op)
+		case "not":
+			return fmt.Sprintf("%s %s", o.Op, o.Args[0].String())
 		default:
 			return fmt.Sprintf("%s%s", o.Op, o.Args[0].String())
 		}
@@ -0,0 +1,60 @@
+package mathematigo
+
+// RangeNode is a range, start:end or start:step:end, which evaluates to the
+// array of values from start to end inclusive. Step is nil when omitted
+type RangeNode struct {
+	Start MathNode // not nil
+	End   MathNode // not nil
+	Step  MathNode
+}
+
+func NewRangeNode(start, end, step MathNode) *RangeNode {
+	return &RangeNode{Start: start, End: end, Step: step}
+}
+
+func (r *RangeNode) String() string {
+	if r.Step == nil {
+		return r.Start.String() + ":" + r.End.String()
+	}
+	return r.Start.String() + ":" + r.Step.String() + ":" + r.End.String()
+}
+
+func (r *RangeNode) ForEach(cb func(MathNode)) {
+	cb(r)
+	r.Start.ForEach(cb)
+	if r.Step != nil {
+		r.Step.ForEach(cb)
+	}
+	r.End.ForEach(cb)
+}
+
+func (r *RangeNode) Equal(other MathNode) bool {
+	otherRange, ok := other.(*RangeNode)
+	if !ok {
+		return false
+	}
+
+	if (r.Step == nil) != (otherRange.Step == nil) {
+		return false
+	}
+	if r.Step != nil && !r.Step.Equal(otherRange.Step) {
+		return false
+	}
+
+	return r.Start.Equal(otherRange.Start) && r.End.Equal(otherRange.End)
+}
+
+func (r *RangeNode) Transform(fn func(MathNode) MathNode) MathNode {
+	res := fn(r)
+	if res != r {
+		return res
+	}
+	r.Start = r.Start.Transform(fn)
+	if r.Step != nil {
+		r.Step = r.Step.Transform(fn)
+	}
+	r.End = r.End.Transform(fn)
+	return r
+}
+
+var _ MathNode = (*RangeNode)(nil)
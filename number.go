@@ -0,0 +1,35 @@
+package mathematigo
+
+// NumberType selects how number literals are represented, see ParseNumbers
+// and WithNumbers
+type NumberType int
+
+const (
+	// NumberFloat parses and evaluates numbers as float64, the default
+	NumberFloat NumberType = iota
+	// NumberBigNumber keeps literals as exact decimal text and evaluates them
+	// as *big.Float at the evaluator's precision
+	NumberBigNumber
+	// NumberFraction keeps literals as exact decimal text and evaluates them
+	// as *big.Rat, so arithmetic on them is exact
+	NumberFraction
+	// NumberInt parses integral literals into IntNodes and evaluates integers
+	// as *big.Int, so integer arithmetic is exact and only falls back to
+	// float64 when the result is not an integer, e.g. 7 / 2
+	NumberInt
+)
+
+func (t NumberType) String() string {
+	switch t {
+	case NumberFloat:
+		return "number"
+	case NumberBigNumber:
+		return "BigNumber"
+	case NumberFraction:
+		return "Fraction"
+	case NumberInt:
+		return "bigint"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,676 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"strings"
+)
+
+var ErrInvalidOperand = errors.New("invalid operand")
+
+// epsilon is the relative tolerance used when comparing numbers, the same
+// default mathjs uses so that 0.1 + 0.2 == 0.3 holds
+const epsilon = 1e-12
+
+type unaryOperator func(a Value) (Value, error)
+type binaryOperator func(a, b Value) (Value, error)
+
+var unaryOperators = map[OperatorFnName]unaryOperator{
+	OperatorFnUnaryMinus: numericUnary(OperatorFnUnaryMinus, unaryImpl{
+		integer:  func(x *big.Int) (Value, error) { return new(big.Int).Neg(x), nil },
+		float:    func(x float64) (Value, error) { return -x, nil },
+		fraction: func(x *big.Rat) (Value, error) { return new(big.Rat).Neg(x), nil },
+		big:      func(x *big.Float) (Value, error) { return new(big.Float).Neg(x), nil },
+		complex:  func(z complex128) (Value, error) { return -z, nil },
+		unit:     unitNegate,
+	}),
+	OperatorFnFactorial: numericUnary(OperatorFnFactorial, unaryImpl{
+		integer:  intFactorial,
+		float:    func(x float64) (Value, error) { return wrapFloat(factorialFloat(x)) },
+		fraction: ratFactorial,
+		big:      bigFactorial,
+	}),
+	OperatorFnBitNot: numericUnary(OperatorFnBitNot, unaryImpl{
+		integer: func(x *big.Int) (Value, error) { return new(big.Int).Not(x), nil },
+		float: func(x float64) (Value, error) {
+			i, ok := floatToInteger(x)
+			if !ok {
+				return nil, integersExpectedErr(OperatorFnBitNot)
+			}
+			return float64(^i), nil
+		},
+		fraction: func(x *big.Rat) (Value, error) {
+			return ratBitwise(OperatorFnBitNot, x, x, func(z, x, _ *big.Int) *big.Int { return z.Not(x) })
+		},
+		big: func(x *big.Float) (Value, error) {
+			return bigBitwise(OperatorFnBitNot, x, x, func(z, x, _ *big.Int) *big.Int { return z.Not(x) })
+		},
+	}),
+	OperatorFnNot: func(a Value) (Value, error) {
+		x, err := truthy(a)
+		if err != nil {
+			return nil, err
+		}
+		return !x, nil
+	},
+}
+
+var binaryOperators = map[OperatorFnName]binaryOperator{
+	// the evaluators short-circuit and and or, these are used when both sides
+	// are already known, e.g. when called as functions
+	OperatorFnAnd: logical(func(x, y bool) bool { return x && y }),
+	OperatorFnOr:  logical(func(x, y bool) bool { return x || y }),
+	OperatorFnXor: logical(func(x, y bool) bool { return x != y }),
+	OperatorFnBitOr: numericBinary(OperatorFnBitOr, binaryImpl{
+		integer: intArith((*big.Int).Or),
+		float: func(x, y float64) (Value, error) {
+			return floatBitwise(OperatorFnBitOr, x, y, func(i, j int64) int64 { return i | j })
+		},
+		fraction: func(x, y *big.Rat) (Value, error) { return ratBitwise(OperatorFnBitOr, x, y, (*big.Int).Or) },
+		big:      func(x, y *big.Float) (Value, error) { return bigBitwise(OperatorFnBitOr, x, y, (*big.Int).Or) },
+	}),
+	OperatorFnBitAnd: numericBinary(OperatorFnBitAnd, binaryImpl{
+		integer: intArith((*big.Int).And),
+		float: func(x, y float64) (Value, error) {
+			return floatBitwise(OperatorFnBitAnd, x, y, func(i, j int64) int64 { return i & j })
+		},
+		fraction: func(x, y *big.Rat) (Value, error) { return ratBitwise(OperatorFnBitAnd, x, y, (*big.Int).And) },
+		big:      func(x, y *big.Float) (Value, error) { return bigBitwise(OperatorFnBitAnd, x, y, (*big.Int).And) },
+	}),
+	OperatorFnBitXor: numericBinary(OperatorFnBitXor, binaryImpl{
+		integer: intArith((*big.Int).Xor),
+		float: func(x, y float64) (Value, error) {
+			return floatBitwise(OperatorFnBitXor, x, y, func(i, j int64) int64 { return i ^ j })
+		},
+		fraction: func(x, y *big.Rat) (Value, error) { return ratBitwise(OperatorFnBitXor, x, y, (*big.Int).Xor) },
+		big:      func(x, y *big.Float) (Value, error) { return bigBitwise(OperatorFnBitXor, x, y, (*big.Int).Xor) },
+	}),
+	OperatorFnLeftShift: numericBinary(OperatorFnLeftShift, binaryImpl{
+		integer: intShift(OperatorFnLeftShift, (*big.Int).Lsh),
+		float: func(x, y float64) (Value, error) {
+			return floatShift(OperatorFnLeftShift, x, y, func(i int64, n uint) int64 { return i << n })
+		},
+		fraction: func(x, y *big.Rat) (Value, error) { return ratShift(OperatorFnLeftShift, x, y, (*big.Int).Lsh) },
+		big:      func(x, y *big.Float) (Value, error) { return bigShift(OperatorFnLeftShift, x, y, (*big.Int).Lsh) },
+	}),
+	OperatorFnRightArithShift: numericBinary(OperatorFnRightArithShift, binaryImpl{
+		integer: intShift(OperatorFnRightArithShift, (*big.Int).Rsh),
+		float: func(x, y float64) (Value, error) {
+			return floatShift(OperatorFnRightArithShift, x, y, func(i int64, n uint) int64 { return i >> n })
+		},
+		fraction: func(x, y *big.Rat) (Value, error) { return ratShift(OperatorFnRightArithShift, x, y, (*big.Int).Rsh) },
+		big:      func(x, y *big.Float) (Value, error) { return bigShift(OperatorFnRightArithShift, x, y, (*big.Int).Rsh) },
+	}),
+	// like in mathjs the logical shift is only defined for numbers and
+	// bigints, the other kinds have no fixed width to shift zeros into. it
+	// uses the same 64 bits as the other bitwise operators: x is shifted as
+	// an unsigned 64 bit integer, so -8 >>> 1 is 2^63 - 4 and a count of 64
+	// or more gives 0
+	OperatorFnRightLogShift: numericBinary(OperatorFnRightLogShift, binaryImpl{
+		integer: intRightLogShift,
+		float: func(x, y float64) (Value, error) {
+			return floatShift(OperatorFnRightLogShift, x, y, func(i int64, n uint) int64 { return int64(uint64(i) >> n) })
+		},
+	}),
+	OperatorFnAdd: numericBinary(OperatorFnAdd, binaryImpl{
+		integer:  intArith((*big.Int).Add),
+		float:    func(x, y float64) (Value, error) { return x + y, nil },
+		fraction: func(x, y *big.Rat) (Value, error) { return new(big.Rat).Add(x, y), nil },
+		big:      func(x, y *big.Float) (Value, error) { return bigArith(x, y, (*big.Float).Add) },
+		complex:  func(x, y complex128) (Value, error) { return x + y, nil },
+		unit:     unitAdd(OperatorFnAdd, 1),
+	}),
+	OperatorFnSubtract: numericBinary(OperatorFnSubtract, binaryImpl{
+		integer:  intArith((*big.Int).Sub),
+		float:    func(x, y float64) (Value, error) { return x - y, nil },
+		fraction: func(x, y *big.Rat) (Value, error) { return new(big.Rat).Sub(x, y), nil },
+		big:      func(x, y *big.Float) (Value, error) { return bigArith(x, y, (*big.Float).Sub) },
+		complex:  func(x, y complex128) (Value, error) { return x - y, nil },
+		unit:     unitAdd(OperatorFnSubtract, -1),
+	}),
+	OperatorFnMultiply: numericBinary(OperatorFnMultiply, binaryImpl{
+		integer:  intArith((*big.Int).Mul),
+		float:    func(x, y float64) (Value, error) { return x * y, nil },
+		fraction: func(x, y *big.Rat) (Value, error) { return new(big.Rat).Mul(x, y), nil },
+		big:      func(x, y *big.Float) (Value, error) { return bigArith(x, y, (*big.Float).Mul) },
+		complex:  func(x, y complex128) (Value, error) { return x * y, nil },
+		unit:     unitProduct(OperatorFnMultiply, 1),
+	}),
+	OperatorFnDivide: numericBinary(OperatorFnDivide, binaryImpl{
+		integer:  intQuo,
+		float:    func(x, y float64) (Value, error) { return x / y, nil },
+		fraction: ratQuo,
+		big:      func(x, y *big.Float) (Value, error) { return bigArith(x, y, (*big.Float).Quo) },
+		complex:  func(x, y complex128) (Value, error) { return x / y, nil },
+		unit:     unitProduct(OperatorFnDivide, -1),
+	}),
+	OperatorFnMod: numericBinary(OperatorFnMod, binaryImpl{
+		integer:  intMod,
+		float:    func(x, y float64) (Value, error) { return floorMod(x, y), nil },
+		fraction: ratMod,
+		big:      bigMod,
+	}),
+	OperatorFnPower: numericBinary(OperatorFnPower, binaryImpl{
+		integer:  intPow,
+		float:    func(x, y float64) (Value, error) { return floatPow(x, y), nil },
+		fraction: ratPow,
+		big:      bigPow,
+		complex:  func(x, y complex128) (Value, error) { return complexPow(x, y), nil },
+		unit:     unitPow,
+	}),
+	OperatorFnUnequal: opUnequal,
+	OperatorFnEqual:   opEqual,
+	OperatorFnGt:      comparison(OperatorFnGt, func(cmp int) bool { return cmp > 0 }),
+	OperatorFnGteq:    comparison(OperatorFnGteq, func(cmp int) bool { return cmp >= 0 }),
+	OperatorFnLt:      comparison(OperatorFnLt, func(cmp int) bool { return cmp < 0 }),
+	OperatorFnLteq:    comparison(OperatorFnLteq, func(cmp int) bool { return cmp <= 0 }),
+	OperatorFnTo:      unitConvert,
+}
+
+// typeOf names the type of a value the way mathjs does, for error messages
+func typeOf(v Value) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return "number"
+	case *big.Int:
+		return "bigint"
+	case *big.Rat:
+		return "Fraction"
+	case *big.Float:
+		return "BigNumber"
+	case complex128:
+		return "Complex"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case Function:
+		return "function"
+	case *Unit:
+		return "Unit"
+	case []Value:
+		return "Array"
+	case map[string]Value:
+		return "Object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func invalidOperandErr(fn OperatorFnName, args ...Value) error {
+	types := make([]string, 0, len(args))
+	for _, a := range args {
+		types = append(types, typeOf(a))
+	}
+	return fmt.Errorf("%w: cannot apply %s to (%s)", ErrInvalidOperand, fn, strings.Join(types, ", "))
+}
+
+func integersExpectedErr(fn OperatorFnName) error {
+	return fmt.Errorf("%w: integers expected in function %s", ErrInvalidOperand, fn)
+}
+
+// numberKind orders the numeric types. when two kinds meet in an operator
+// the lower one is converted to the higher one
+type numberKind int
+
+const (
+	notANumber numberKind = iota
+	kindInt
+	kindFloat
+	kindFraction
+	kindBig
+	kindComplex
+)
+
+func kindOf(v Value) numberKind {
+	switch v.(type) {
+	case *big.Int:
+		return kindInt
+	case float64, bool, nil:
+		return kindFloat
+	case *big.Rat:
+		return kindFraction
+	case *big.Float:
+		return kindBig
+	case complex128:
+		return kindComplex
+	default:
+		return notANumber
+	}
+}
+
+// toNumber converts v to a float64. like mathjs, booleans count as 1 and 0
+// and null counts as 0. bigints, Fractions and BigNumbers are rounded to the
+// nearest float64
+func toNumber(v Value) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	case nil:
+		return 0, true
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(x).Float64()
+		return f, true
+	case *big.Rat:
+		f, _ := x.Float64()
+		return f, true
+	case *big.Float:
+		f, _ := x.Float64()
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
+// toInteger converts v to an int64 if it is a number without a fractional part
+func toInteger(v Value) (int64, bool) {
+	x, ok := toNumber(v)
+	if !ok {
+		return 0, false
+	}
+	return floatToInteger(x)
+}
+
+func floatToInteger(x float64) (int64, bool) {
+	if x != math.Trunc(x) || x < math.MinInt64 || x >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(x), true
+}
+
+// promote converts a and b to the higher of their two number kinds. new
+// BigNumbers get the precision of the BigNumber operand
+func promote(a, b Value) (Value, Value, numberKind) {
+	ka, kb := kindOf(a), kindOf(b)
+	if ka == notANumber || kb == notANumber {
+		return nil, nil, notANumber
+	}
+
+	// a Fraction or BigNumber cannot hold NaN, and a Fraction cannot hold
+	// Inf either. those operands keep the operation on float64
+	switch kind := max(ka, kb); kind {
+	case kindInt:
+		return a, b, kind
+	case kindFraction:
+		x, okX := toRat(a)
+		y, okY := toRat(b)
+		if okX && okY {
+			return x, y, kind
+		}
+	case kindBig:
+		x, okX := toBig(a, bigPrecOf(a, b))
+		y, okY := toBig(b, bigPrecOf(a, b))
+		if okX && okY {
+			return x, y, kind
+		}
+	case kindComplex:
+		x, _ := toComplex(a)
+		y, _ := toComplex(b)
+		return x, y, kind
+	}
+
+	x, _ := toNumber(a)
+	y, _ := toNumber(b)
+	return x, y, kindFloat
+}
+
+// unaryImpl holds an operator's implementation per number kind. a nil entry
+// means the kind is not supported, except for bigints, which then use the
+// float64 implementation, and complex numbers, which do so when they have no
+// imaginary part. the unit entry of a binary operator gets both operands as
+// soon as one is a Unit
+type unaryImpl struct {
+	integer  func(x *big.Int) (Value, error)
+	float    func(x float64) (Value, error)
+	fraction func(x *big.Rat) (Value, error)
+	big      func(x *big.Float) (Value, error)
+	complex  func(z complex128) (Value, error)
+	unit     func(u *Unit) (Value, error)
+}
+
+type binaryImpl struct {
+	integer  func(x, y *big.Int) (Value, error)
+	float    func(x, y float64) (Value, error)
+	fraction func(x, y *big.Rat) (Value, error)
+	big      func(x, y *big.Float) (Value, error)
+	complex  func(x, y complex128) (Value, error)
+	unit     func(a, b Value) (Value, error)
+}
+
+func numericUnary(fn OperatorFnName, impl unaryImpl) unaryOperator {
+	return func(a Value) (Value, error) {
+		if u, ok := a.(*Unit); ok && impl.unit != nil {
+			return impl.unit(u)
+		}
+
+		switch kindOf(a) {
+		case kindInt:
+			if impl.integer != nil {
+				return impl.integer(a.(*big.Int))
+			}
+			x, _ := toNumber(a)
+			return impl.float(x)
+		case kindFloat:
+			x, _ := toNumber(a)
+			return impl.float(x)
+		case kindFraction:
+			if impl.fraction != nil {
+				return impl.fraction(a.(*big.Rat))
+			}
+		case kindBig:
+			if impl.big != nil {
+				return impl.big(a.(*big.Float))
+			}
+		case kindComplex:
+			if impl.complex != nil {
+				return impl.complex(a.(complex128))
+			}
+			x, err := complexReal(fn, a.(complex128))
+			if err != nil {
+				return nil, err
+			}
+			return impl.float(x)
+		}
+		return nil, invalidOperandErr(fn, a)
+	}
+}
+
+func numericBinary(fn OperatorFnName, impl binaryImpl) binaryOperator {
+	return func(a, b Value) (Value, error) {
+		if isUnit(a) || isUnit(b) {
+			if impl.unit != nil {
+				return impl.unit(a, b)
+			}
+			return nil, invalidOperandErr(fn, a, b)
+		}
+
+		x, y, kind := promote(a, b)
+		switch kind {
+		case kindInt:
+			if impl.integer != nil {
+				return impl.integer(x.(*big.Int), y.(*big.Int))
+			}
+			fx, _ := toNumber(x)
+			fy, _ := toNumber(y)
+			return impl.float(fx, fy)
+		case kindFloat:
+			return impl.float(x.(float64), y.(float64))
+		case kindFraction:
+			if impl.fraction != nil {
+				return impl.fraction(x.(*big.Rat), y.(*big.Rat))
+			}
+		case kindBig:
+			if impl.big != nil {
+				return impl.big(x.(*big.Float), y.(*big.Float))
+			}
+		case kindComplex:
+			if impl.complex != nil {
+				return impl.complex(x.(complex128), y.(complex128))
+			}
+			return realOnly(fn, x.(complex128), y.(complex128), impl.float)
+		}
+		return nil, invalidOperandErr(fn, a, b)
+	}
+}
+
+// realOnly applies the float64 implementation of an operator that is only
+// defined on the real numbers to complex numbers without an imaginary part
+func realOnly(fn OperatorFnName, x, y complex128, float func(x, y float64) (Value, error)) (Value, error) {
+	rx, err := complexReal(fn, x)
+	if err != nil {
+		return nil, err
+	}
+	ry, err := complexReal(fn, y)
+	if err != nil {
+		return nil, err
+	}
+	return float(rx, ry)
+}
+
+func wrapFloat(x float64, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func factorialFloat(x float64) (float64, error) {
+	if x < 0 {
+		return 0, fmt.Errorf("%w: value must be non-negative in function %s", ErrInvalidOperand, OperatorFnFactorial)
+	}
+	if x != math.Trunc(x) {
+		return math.Gamma(x + 1), nil
+	}
+	if x > 170 {
+		// 171! does not fit in a float64
+		return math.Inf(1), nil
+	}
+
+	out := 1.0
+	for i := 2.0; i <= x; i++ {
+		out *= i
+	}
+	return out, nil
+}
+
+// floorMod follows mathjs: the result has the sign of the divisor and
+// x mod 0 is x
+func floorMod(x, y float64) float64 {
+	if y == 0 {
+		return x
+	}
+	return x - y*math.Floor(x/y)
+}
+
+func floatBitwise(fn OperatorFnName, x, y float64, op func(i, j int64) int64) (Value, error) {
+	i, okX := floatToInteger(x)
+	j, okY := floatToInteger(y)
+	if !okX || !okY {
+		return nil, integersExpectedErr(fn)
+	}
+	return float64(op(i, j)), nil
+}
+
+// maxShift bounds shift counts. Fractions and BigNumbers are not limited to 64
+// bits and would otherwise grow without bound
+const maxShift = 1 << 16
+
+func shiftCount(fn OperatorFnName, n *big.Int) (uint, error) {
+	if n.Sign() < 0 || n.Cmp(big.NewInt(maxShift)) > 0 {
+		return 0, fmt.Errorf("%w: shift count must be in the range of 0-%d in function %s", ErrArgumentValue, maxShift, fn)
+	}
+	return uint(n.Uint64()), nil
+}
+
+func floatShift(fn OperatorFnName, x, y float64, op func(i int64, n uint) int64) (Value, error) {
+	i, okX := floatToInteger(x)
+	j, okY := floatToInteger(y)
+	if !okX || !okY {
+		return nil, integersExpectedErr(fn)
+	}
+	n, err := shiftCount(fn, big.NewInt(j))
+	if err != nil {
+		return nil, err
+	}
+	return float64(op(i, n)), nil
+}
+
+// nearlyEqual reports whether x and y are equal within the relative
+// tolerance epsilon
+func nearlyEqual(x, y float64) bool {
+	if x == y {
+		return true
+	}
+	if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return false
+	}
+	diff := math.Abs(x - y)
+	if diff < 2.220446049250313e-16 {
+		return true
+	}
+	return diff <= math.Max(math.Abs(x), math.Abs(y))*epsilon
+}
+
+func compareFloats(x, y float64) (int, bool) {
+	switch {
+	case math.IsNaN(x) || math.IsNaN(y):
+		return 0, false
+	case nearlyEqual(x, y):
+		return 0, true
+	case x < y:
+		return -1, true
+	default:
+		return 1, true
+	}
+}
+
+// compareNumbers orders two numbers of any kind. ok is false when the values
+// cannot be ordered, for example when either one is NaN
+func compareNumbers(a, b Value) (cmp int, ok bool) {
+	x, y, kind := promote(a, b)
+	switch kind {
+	case kindInt:
+		return x.(*big.Int).Cmp(y.(*big.Int)), true
+	case kindFloat:
+		return compareFloats(x.(float64), y.(float64))
+	case kindFraction:
+		return x.(*big.Rat).Cmp(y.(*big.Rat)), true
+	case kindBig:
+		return bigCompare(x.(*big.Float), y.(*big.Float)), true
+	case kindComplex:
+		// only reached for equality, comparison rejects complex numbers
+		if complexEqual(x.(complex128), y.(complex128)) {
+			return 0, true
+		}
+		return 0, false
+	default:
+		return 0, false
+	}
+}
+
+// comparison builds an ordering operator. strings are ordered lexically and
+// only against other strings, units only against units of the same dimension
+func comparison(fn OperatorFnName, test func(cmp int) bool) binaryOperator {
+	return func(a, b Value) (Value, error) {
+		if isUnit(a) || isUnit(b) {
+			cmp, ok, err := compareUnits(fn, a, b)
+			if err != nil {
+				return nil, err
+			}
+			return ok && test(cmp), nil
+		}
+
+		if sa, isStr := a.(string); isStr {
+			sb, isStr := b.(string)
+			if !isStr {
+				return nil, invalidOperandErr(fn, a, b)
+			}
+			return test(strings.Compare(sa, sb)), nil
+		}
+
+		if kindOf(a) == notANumber || kindOf(b) == notANumber {
+			return nil, invalidOperandErr(fn, a, b)
+		}
+		if x, y, kind := promote(a, b); kind == kindComplex {
+			return realOnly(fn, x.(complex128), y.(complex128), func(x, y float64) (Value, error) {
+				cmp, ok := compareFloats(x, y)
+				return ok && test(cmp), nil
+			})
+		}
+
+		cmp, ok := compareNumbers(a, b)
+		return ok && test(cmp), nil
+	}
+}
+
+// valuesEqual is lenient about types: values of different kinds are simply
+// not equal. strings are not converted to numbers as in mathjs, so "1" == 1
+// is false
+func valuesEqual(a, b Value) bool {
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	}
+
+	if _, isStr := b.(string); isStr || b == nil {
+		return false
+	}
+
+	if isUnit(a) || isUnit(b) {
+		cmp, ok, err := compareUnits(OperatorFnEqual, a, b)
+		return err == nil && ok && cmp == 0
+	}
+
+	cmp, ok := compareNumbers(a, b)
+	return ok && cmp == 0
+}
+
+func opEqual(a, b Value) (Value, error) {
+	return valuesEqual(a, b), nil
+}
+
+func opUnequal(a, b Value) (Value, error) {
+	return !valuesEqual(a, b), nil
+}
+
+// truthy decides which branch of a conditional is taken and is what the
+// logical operators work on. like mathjs, numbers are true unless they are
+// zero or NaN, strings unless they are empty and null is false
+func truthy(v Value) (bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case nil:
+		return false, nil
+	case float64:
+		return x != 0 && !math.IsNaN(x), nil
+	case *big.Int:
+		return x.Sign() != 0, nil
+	case *big.Rat:
+		return x.Sign() != 0, nil
+	case *big.Float:
+		return x.Sign() != 0, nil
+	case complex128:
+		return x != 0 && !cmplx.IsNaN(x), nil
+	case string:
+		return x != "", nil
+	case *Unit:
+		return x.hasValue && x.value != 0 && !math.IsNaN(x.value), nil
+	default:
+		return false, fmt.Errorf("%w: cannot convert %s to a boolean", ErrInvalidOperand, typeOf(v))
+	}
+}
+
+func logical(op func(x, y bool) bool) binaryOperator {
+	return func(a, b Value) (Value, error) {
+		x, err := truthy(a)
+		if err != nil {
+			return nil, err
+		}
+		y, err := truthy(b)
+		if err != nil {
+			return nil, err
+		}
+		return op(x, y), nil
+	}
+}
+
+// shortCircuits maps and and or to the value of the left operand that
+// decides the result without looking at the right one
+var shortCircuits = map[OperatorFnName]bool{
+	OperatorFnAnd: false,
+	OperatorFnOr:  true,
+}
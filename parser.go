@@ -3,6 +3,7 @@ package mathematigo
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 )
 
@@ -14,6 +15,9 @@ const (
 	ParseErrEmpty           ParseErrType = "EMPTY"
 	ParseErrEnd             ParseErrType = "END"
 	ParseErrUnendedFunction ParseErrType = "UNENDED_FUNCTION"
+	ParseErrUnendedArray    ParseErrType = "UNENDED_ARRAY"
+	ParseErrUnendedIndex    ParseErrType = "UNENDED_INDEX"
+	ParseErrUnendedObject   ParseErrType = "UNENDED_OBJECT"
 	ParseErrUnexpected      ParseErrType = "UNEXPECTED"
 )
 
@@ -32,6 +36,12 @@ func (pe *ParseErr) Error() string {
 		return "unexpected end of expression"
 	case ParseErrUnexpected:
 		return fmt.Sprintf("unexpected token: '%s'", string(pe.chars))
+	case ParseErrUnendedArray:
+		return "expected ']' to end the array"
+	case ParseErrUnendedIndex:
+		return "expected ']' to end the index"
+	case ParseErrUnendedObject:
+		return "expected '}' to end the object"
 	default:
 		return ""
 	}
@@ -41,6 +51,9 @@ var (
 	ErrEmptyExpression = &ParseErr{Type: ParseErrEmpty}
 	ErrUnexpectedEnd   = &ParseErr{Type: ParseErrEmpty}
 	ErrUnendedFunction = &ParseErr{Type: ParseErrUnendedFunction}
+	ErrUnendedArray    = &ParseErr{Type: ParseErrUnendedArray}
+	ErrUnendedIndex    = &ParseErr{Type: ParseErrUnendedIndex}
+	ErrUnendedObject   = &ParseErr{Type: ParseErrUnendedObject}
 )
 
 func newUnexpectedTokenErr(chars []rune) *ParseErr {
@@ -53,6 +66,22 @@ func newUnexpectedTokenErr(chars []rune) *ParseErr {
 type parser struct {
 	tokens  []Token
 	current int
+
+	numbers NumberType
+
+	// isAlpha is set by ParseIdentifiers and handed to the scanner
+	isAlpha func(r rune) bool
+
+	// inConditional is set while parsing the true branch of a conditional,
+	// where a ":" ends the branch instead of starting a range
+	inConditional bool
+
+	// comments is set by ParseComments. trivia holds the comment tokens
+	// taken out of tokens and lines the first line of every statement, which
+	// is how the comments find their statement
+	comments bool
+	trivia   []trivia
+	lines    []int
 }
 
 func newParser(tokens []Token) *parser {
@@ -61,15 +90,58 @@ func newParser(tokens []Token) *parser {
 	}
 }
 
-func Parse(val string) (MathNode, error) {
+type ParseOption func(*parser)
+
+// ParseNumbers selects the node number literals are parsed into. with
+// NumberBigNumber or NumberFraction they become BigNumberNodes or
+// FractionNodes holding the literal's exact decimal text instead of
+// FloatNodes. with NumberInt integral literals become IntNodes, or
+// BigIntNodes past int64, and the others stay FloatNodes
+func ParseNumbers(t NumberType) ParseOption {
+	return func(p *parser) {
+		p.numbers = t
+	}
+}
+
+// ParseIdentifiers replaces IsAlpha as the test for the characters
+// identifiers are made of, e.g. to accept any unicode letter. digits are
+// allowed after the first character either way, and characters that have a
+// meaning of their own, like operators, never start an identifier
+func ParseIdentifiers(isAlpha func(r rune) bool) ParseOption {
+	return func(p *parser) {
+		p.isAlpha = isAlpha
+	}
+}
+
+// ParseComments keeps "#" comments instead of dropping them. the root is then
+// always a BlockNode, even for a single statement without comments, and each
+// comment is attached to a statement of it by index, see BlockNode.Comments.
+// a comment at the end of a line belongs to the statement on that line, one
+// on a line of its own to the statement after it
+func ParseComments() ParseOption {
+	return func(p *parser) {
+		p.comments = true
+	}
+}
+
+func Parse(val string, opts ...ParseOption) (MathNode, error) {
+	p := newParser(nil)
+	for _, opt := range opts {
+		opt(p)
+	}
+
 	s := NewScanner(val)
+	s.keepComments = p.comments
+	if p.isAlpha != nil {
+		s.isAlpha = p.isAlpha
+	}
 
 	toks, err := s.scanTokens()
 	if err != nil {
 		return nil, err
 	}
 
-	p := newParser(toks)
+	p.tokens, p.trivia = splitTrivia(toks)
 
 	ex, err := p.parse()
 	if err != nil {
@@ -116,12 +188,19 @@ func (p *parser) parse() (MathNode, error) {
 		return nil, ErrUnexpectedEnd
 	}
 
+	if p.comments && len(b.Blocks) > 0 {
+		// the comments live on the block, which is kept whether or not
+		// there are any so the root has the same type for every input
+		p.attachComments(b)
+		return b, nil
+	}
+
 	switch len(b.Blocks) {
 	case 0:
 		return nil, ErrEmptyExpression
 	case 1:
-		isLeading := first.Type == NewLine
-		isTrailing := p.tokens[p.current-1].Type == NewLine
+		isLeading := isSeparator(first)
+		isTrailing := isSeparator(p.tokens[p.current-1])
 		if isLeading || isTrailing {
 			// keep block
 			return b, nil
@@ -134,17 +213,20 @@ func (p *parser) parse() (MathNode, error) {
 }
 
 func (p *parser) expression() (*BlockNode, error) {
-	// expression → NEWLINE* block (NEWLINE+ block)* NEWLINE*
+	// expression → separator* block (separator+ block)* separator*
+	// separator  → NEWLINE | ";"
 
 	b := &BlockNode{}
+	hidden := []bool{}
 
 	for !p.isAtEnd() {
-		p.skipNewLines()
+		p.skipSeparators()
 
 		if p.isAtEnd() {
 			break
 		}
 
+		line := p.tokens[p.current].Line
 		part, err := p.block()
 
 		if err != nil {
@@ -152,20 +234,171 @@ func (p *parser) expression() (*BlockNode, error) {
 		}
 
 		b.Blocks = append(b.Blocks, part)
+		p.lines = append(p.lines, line)
+
+		// like in mathjs, a statement that ends in a semicolon is hidden
+		next, ok := p.peek()
+		hidden = append(hidden, ok && next.Type == Semi)
+	}
+
+	if slices.Contains(hidden, true) {
+		b.Hidden = hidden
 	}
 
 	return b, nil
 
 }
 
+func isSeparator(t Token) bool {
+	return t.Type == NewLine || t.Type == Semi
+}
+
+func (p *parser) skipSeparators() {
+	for next, ok := p.peek(); ok && isSeparator(next); next, ok = p.peek() {
+		p.advance()
+	}
+}
+
 func (p *parser) block() (MathNode, error) {
-	return p.or()
+	// blocks are also what parentheses, brackets and arguments hold, in there
+	// a ":" is a range again
+	defer func(prev bool) { p.inConditional = prev }(p.inConditional)
+	p.inConditional = false
+
+	return p.assignment()
+}
+
+func (p *parser) assignment() (MathNode, error) {
+	// assignment → SYMBOL "=" assignment
+	//            | SYMBOL "(" ( SYMBOL ( "," SYMBOL )* )? ")" "=" assignment
+	//            | conditional ;
+
+	target, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+
+	next, ok := p.peek()
+	if !ok || next.Type != Eq {
+		return target, nil
+	}
+
+	// the left side parses as a symbol or a call, check it before going on
+	var params []string
+	switch t := target.(type) {
+	case *SymbolNode:
+	case *FunctionNode:
+		params = make([]string, 0, len(t.Args))
+		for _, arg := range t.Args {
+			param, ok := arg.(*SymbolNode)
+			if !ok {
+				return nil, newUnexpectedTokenErr(next.Text)
+			}
+			params = append(params, param.Name)
+		}
+	default:
+		return nil, newUnexpectedTokenErr(next.Text)
+	}
+
+	p.advance() // consume "="
+	p.skipNewLines()
+
+	// right associative, a = b = 1 assigns both
+	value, err := p.assignment()
+	if err != nil {
+		return nil, err
+	}
+
+	if fn, ok := target.(*FunctionNode); ok {
+		return &FunctionAssignmentNode{Name: fn.Fn.Name, Params: params, Expr: value}, nil
+	}
+	return &AssignmentNode{Object: target.(*SymbolNode), Value: value}, nil
+}
+
+func (p *parser) conditional() (MathNode, error) {
+	// conditional → logicalOr ( "?" assignment ":" assignment )? ;
+
+	condition, err := p.logicalOr()
+	if err != nil {
+		return nil, err
+	}
+
+	next, ok := p.peek()
+	if !ok || next.Type != Question {
+		return condition, nil
+	}
+	p.advance() // consume "?"
+	p.skipNewLines()
+
+	prev := p.inConditional
+	p.inConditional = true
+	trueExpr, err := p.assignment()
+	p.inConditional = prev
+	if err != nil {
+		return nil, err
+	}
+
+	next, ok = p.peek()
+	if !ok {
+		return nil, ErrUnexpectedEnd
+	}
+	if next.Type != Colon {
+		return nil, newUnexpectedTokenErr(next.Text)
+	}
+	p.advance() // consume ":"
+	p.skipNewLines()
+
+	// right associative, a ? b : c ? d : e nests in the false branch
+	falseExpr, err := p.assignment()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConditionalNode{Condition: condition, TrueExpr: trueExpr, FalseExpr: falseExpr}, nil
+}
+
+func (p *parser) logicalOr() (MathNode, error) {
+	// logicalOr → logicalXor ( "or" logicalXor )* ;
+	return p.keywordOperator(RuneOr, OperatorFnOr, p.logicalXor)
+}
+
+func (p *parser) logicalXor() (MathNode, error) {
+	// logicalXor → logicalAnd ( "xor" logicalAnd )* ;
+	return p.keywordOperator(RuneXor, OperatorFnXor, p.logicalAnd)
+}
+
+func (p *parser) logicalAnd() (MathNode, error) {
+	// logicalAnd → or ( "and" or )* ;
+	return p.keywordOperator(RuneAnd, OperatorFnAnd, p.or)
+}
+
+// keywordOperator parses a left associative chain of operands joined by the
+// keyword, e.g. a and b and c
+func (p *parser) keywordOperator(keyword SmartRune, fn OperatorFnName, operand func() (MathNode, error)) (MathNode, error) {
+	curr, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for next, ok := p.peek(); ok && next.Type == Ident && next.Text.equals(keyword); next, ok = p.peek() {
+		p.advance() // consume operator
+		p.skipNewLines()
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		curr = &OperatorNode{Args: []MathNode{curr, right}, Op: string(next.Text), Fn: fn}
+	}
+
+	return curr, nil
 }
 
 func (p *parser) or() (MathNode, error) {
-	// or → and ( "|" and )* ;
+	// or → bitXor ( "|" bitXor )* ;
 
-	curr, err := p.and()
+	curr, err := p.bitXor()
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +407,7 @@ func (p *parser) or() (MathNode, error) {
 		p.advance() // consume operator
 		p.skipNewLines()
 
-		right, err := p.and()
+		right, err := p.bitXor()
 		if err != nil {
 			return nil, err
 		}
@@ -185,6 +418,29 @@ func (p *parser) or() (MathNode, error) {
 	return curr, nil
 }
 
+func (p *parser) bitXor() (MathNode, error) {
+	// bitXor → and ( "^|" and )* ;
+
+	curr, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for next, ok := p.peek(); ok && next.Type == CaretPipe; next, ok = p.peek() {
+		p.advance() // consume operator
+		p.skipNewLines()
+
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		curr = &OperatorNode{Args: []MathNode{curr, right}, Op: string(next.Text), Fn: OperatorFnBitXor}
+	}
+
+	return curr, nil
+}
+
 func (p *parser) and() (MathNode, error) {
 	// bitwiseAnd → comparison ( "&" comparison )* ;
 
@@ -237,9 +493,9 @@ func (p *parser) equality() (MathNode, error) {
 }
 
 func (p *parser) comparison() (MathNode, error) {
-	// comparison → term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
+	// comparison → shift ( ( ">" | ">=" | "<" | "<=" ) shift )* ;
 
-	curr, err := p.term()
+	curr, err := p.shift()
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +504,7 @@ func (p *parser) comparison() (MathNode, error) {
 		p.advance() // consume operator
 		p.skipNewLines()
 
-		right, err := p.term()
+		right, err := p.shift()
 		if err != nil {
 			return nil, err
 		}
@@ -268,6 +524,105 @@ func (p *parser) comparison() (MathNode, error) {
 	return curr, nil
 }
 
+func (p *parser) shift() (MathNode, error) {
+	// shift → conversion ( ( "<<" | ">>" | ">>>" ) conversion )* ;
+
+	curr, err := p.conversion()
+	if err != nil {
+		return nil, err
+	}
+
+	for next, ok := p.peek(); ok && (next.Type == LtLt || next.Type == GtGt || next.Type == GtGtGt); next, ok = p.peek() {
+		p.advance() // consume operator
+		p.skipNewLines()
+
+		right, err := p.conversion()
+		if err != nil {
+			return nil, err
+		}
+
+		switch next.Type {
+		case LtLt:
+			curr = &OperatorNode{Args: []MathNode{curr, right}, Op: string(next.Text), Fn: OperatorFnLeftShift}
+		case GtGt:
+			curr = &OperatorNode{Args: []MathNode{curr, right}, Op: string(next.Text), Fn: OperatorFnRightArithShift}
+		case GtGtGt:
+			curr = &OperatorNode{Args: []MathNode{curr, right}, Op: string(next.Text), Fn: OperatorFnRightLogShift}
+		}
+	}
+
+	return curr, nil
+}
+
+func (p *parser) conversion() (MathNode, error) {
+	// conversion → range ( ( "to" | "in" ) range )* ;
+
+	curr, err := p.rangeExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	for next, ok := p.peek(); ok && isConversion(next); next, ok = p.peek() {
+		p.advance() // consume operator
+		p.skipNewLines()
+
+		right, err := p.rangeExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		curr = &OperatorNode{Args: []MathNode{curr, right}, Op: string(next.Text), Fn: OperatorFnTo}
+	}
+
+	return curr, nil
+}
+
+func (p *parser) rangeExpr() (MathNode, error) {
+	// range → term ( ":" term ( ":" term )? )? ;
+
+	start, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	if next, ok := p.peek(); !ok || next.Type != Colon || p.inConditional {
+		return start, nil
+	}
+	p.advance() // consume ":"
+
+	second, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	if next, ok := p.peek(); !ok || next.Type != Colon {
+		return &RangeNode{Start: start, End: second}, nil
+	}
+	p.advance() // consume ":"
+
+	end, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	// with three parts the middle one is the step, like in mathjs
+	return &RangeNode{Start: start, Step: second, End: end}, nil
+}
+
+func isConversion(t Token) bool {
+	return t.Type == Ident && (t.Text.equals(RuneTo) || t.Text.equals(RuneIn))
+}
+
+// isReserved reports whether t is an operator keyword, which cannot be used
+// as a symbol
+func isReserved(t Token) bool {
+	if t.Type != Ident {
+		return false
+	}
+	_, ok := ReservedIdentifiers[string(t.Text)]
+	return ok
+}
+
 func (p *parser) term() (MathNode, error) {
 	// term → factor ( ( "-" | "+" ) factor )* ;
 
@@ -399,6 +754,12 @@ func (p *parser) canImplicitMultiply(leftNode MathNode) (bool, error) {
 		return false, nil
 	}
 
+	// 5 cm to inch converts and a and b is logical, neither multiplies by a
+	// symbol
+	if isConversion(right) || isReserved(right) {
+		return false, nil
+	}
+
 	// Only these tokens can start an implicit multiplication
 	switch right.Type {
 	case Ident, Number, OpenParen:
@@ -409,6 +770,88 @@ func (p *parser) canImplicitMultiply(leftNode MathNode) (bool, error) {
 }
 
 func (p *parser) primary() (MathNode, error) {
+	// primary → atom accessors ;
+
+	node, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.accessors(node)
+}
+
+func (p *parser) accessors(node MathNode) (MathNode, error) {
+	// accessors → ( "." IDENT | "[" block ( "," block )* "]" )* ;
+
+	// like mathjs, numbers and keywords cannot be indexed
+	switch node.(type) {
+	case *SymbolNode, *FunctionNode, *ParenthesisNode, *ArrayNode, *ObjectNode, *ConstantNode:
+	default:
+		return node, nil
+	}
+
+	for {
+		next, ok := p.peek()
+		if !ok {
+			return node, nil
+		}
+
+		switch next.Type {
+		case Dot:
+			p.advance()
+			name, ok := p.peek()
+			if !ok || name.Type != Ident {
+				return nil, newUnexpectedTokenErr(next.Text)
+			}
+			p.advance()
+
+			node = &AccessorNode{Object: node, Index: NewPropertyIndexNode(string(name.Text))}
+		case OpenBracket:
+			index, err := p.index()
+			if err != nil {
+				return nil, err
+			}
+
+			node = &AccessorNode{Object: node, Index: index}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *parser) index() (*IndexNode, error) {
+	p.advance() // consume "["
+	p.skipNewLines()
+
+	index := &IndexNode{}
+	for {
+		dim, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+
+		index.Dimensions = append(index.Dimensions, dim)
+		p.skipNewLines()
+
+		next, ok := p.peek()
+		if !ok {
+			return nil, ErrUnendedIndex
+		}
+
+		switch next.Type {
+		case Comma:
+			p.advance()
+			p.skipNewLines()
+		case CloseBracket:
+			p.advance()
+			return index, nil
+		default:
+			return nil, ErrUnendedIndex
+		}
+	}
+}
+
+func (p *parser) atom() (MathNode, error) {
 	curr, ok := p.peek()
 
 	if !ok {
@@ -417,6 +860,9 @@ func (p *parser) primary() (MathNode, error) {
 
 	switch curr.Type {
 	case Ident:
+		if isReserved(curr) {
+			return nil, newUnexpectedTokenErr(curr.Text)
+		}
 		p.advance()
 		if curr.Text.equals(RuneFalse) {
 			b := BooleanNode(false)
@@ -489,6 +935,28 @@ func (p *parser) primary() (MathNode, error) {
 
 		toParse := curr.Text
 
+		// 0b, 0o and 0x literals are integers whatever the number type
+		if len(toParse) > 1 && toParse[0] == '0' && radixLetter(toParse[1]) {
+			return parseRadixInt(string(toParse))
+		}
+
+		switch p.numbers {
+		case NumberBigNumber:
+			if _, err := parseBig(string(toParse), defaultBigPrec); err != nil {
+				return nil, err
+			}
+			return NewBigNumberNode(string(toParse)), nil
+		case NumberFraction:
+			if _, err := parseRat(string(toParse)); err != nil {
+				return nil, err
+			}
+			return NewFractionNode(string(toParse)), nil
+		case NumberInt:
+			if n, ok := parseDecimalInt(string(toParse)); ok {
+				return n, nil
+			}
+		}
+
 		val, err := strconv.ParseFloat(string(toParse), 64)
 
 		if err != nil {
@@ -502,6 +970,10 @@ func (p *parser) primary() (MathNode, error) {
 		p.advance()
 		c := ConstantNode(string(curr.Literal))
 		return &c, nil
+	case OpenBracket:
+		return p.array()
+	case OpenBrace:
+		return p.object()
 	case OpenParen:
 		p.advance()
 		p.skipNewLines()
@@ -526,8 +998,141 @@ func (p *parser) primary() (MathNode, error) {
 	}
 }
 
+func (p *parser) array() (MathNode, error) {
+	// array → "[" ( row ( ";" row )* )? "]" ;
+	// row   → block ( "," block )* ;
+
+	p.advance() // consume "["
+	p.skipNewLines()
+
+	if next, ok := p.peek(); ok && next.Type == CloseBracket {
+		p.advance()
+		return &ArrayNode{}, nil
+	}
+
+	rows := []*ArrayNode{{}}
+	for {
+		item, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+
+		row := rows[len(rows)-1]
+		row.Items = append(row.Items, item)
+		p.skipNewLines()
+
+		next, ok := p.peek()
+		if !ok {
+			return nil, ErrUnendedArray
+		}
+
+		switch next.Type {
+		case Comma:
+			p.advance()
+		case Semi:
+			p.advance()
+			rows = append(rows, &ArrayNode{})
+		case CloseBracket:
+			p.advance()
+			if len(rows) == 1 {
+				return rows[0], nil
+			}
+
+			// [1, 2; 3, 4] is the matrix [[1, 2], [3, 4]]
+			out := &ArrayNode{Items: make([]MathNode, 0, len(rows))}
+			for _, row := range rows {
+				out.Items = append(out.Items, row)
+			}
+			return out, nil
+		default:
+			return nil, ErrUnendedArray
+		}
+
+		p.skipNewLines()
+	}
+}
+
+func (p *parser) object() (MathNode, error) {
+	// object   → "{" ( property ( "," property )* )? "}" ;
+	// property → ( IDENT | STRING ) ":" block ;
+
+	p.advance() // consume "{"
+	p.skipNewLines()
+
+	out := &ObjectNode{}
+	if next, ok := p.peek(); ok && next.Type == CloseBrace {
+		p.advance()
+		return out, nil
+	}
+
+	for {
+		key, ok := p.peek()
+		if !ok {
+			return nil, ErrUnendedObject
+		}
+
+		var name string
+		switch key.Type {
+		case Ident:
+			name = string(key.Text)
+		case String:
+			name = string(key.Literal)
+		default:
+			return nil, newUnexpectedTokenErr(key.Text)
+		}
+		p.advance()
+
+		if colon, ok := p.peek(); !ok || colon.Type != Colon {
+			return nil, ErrUnendedObject
+		}
+		p.advance()
+		p.skipNewLines()
+
+		value, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+
+		// like in mathjs, a repeated key keeps its place and takes the last value
+		if i := slices.IndexFunc(out.Properties, func(p ObjectProperty) bool { return p.Key == name }); i >= 0 {
+			out.Properties[i].Value = value
+		} else {
+			out.Properties = append(out.Properties, ObjectProperty{Key: name, Value: value})
+		}
+		p.skipNewLines()
+
+		next, ok := p.peek()
+		if !ok {
+			return nil, ErrUnendedObject
+		}
+
+		switch next.Type {
+		case Comma:
+			p.advance()
+			p.skipNewLines()
+		case CloseBrace:
+			p.advance()
+			return out, nil
+		default:
+			return nil, ErrUnendedObject
+		}
+	}
+}
+
 func (p *parser) unary() (MathNode, error) {
-	if next, ok := p.peek(); ok && next.Type == Minus {
+	if next, ok := p.peek(); ok && next.Type == Ident && next.Text.equals(RuneNot) {
+		p.advance()
+		p.skipNewLines()
+
+		content, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &OperatorNode{Args: []MathNode{content}, Op: string(next.Text), Fn: OperatorFnNot}, nil
+	}
+
+	if next, ok := p.peek(); ok && (next.Type == Minus || next.Type == Tilde) {
 		p.advance()
 		p.skipNewLines()
 
@@ -537,6 +1142,9 @@ func (p *parser) unary() (MathNode, error) {
 			return nil, err
 		}
 
+		if next.Type == Tilde {
+			return &OperatorNode{Args: []MathNode{content}, Op: string(next.Text), Fn: OperatorFnBitNot}, nil
+		}
 		return &OperatorNode{Args: []MathNode{content}, Op: string(next.Text), Fn: OperatorFnUnaryMinus}, nil
 	} else {
 		return p.postfix()
@@ -564,3 +1172,65 @@ func (p *parser) skipNewLines() {
 		p.advance()
 	}
 }
+
+// trivia is a comment token taken out of the token stream. trailing comments
+// follow other tokens on their line
+type trivia struct {
+	tok      Token
+	trailing bool
+}
+
+// splitTrivia separates the comments from the tokens the grammar works on
+func splitTrivia(toks []Token) ([]Token, []trivia) {
+	var comments []trivia
+	out := toks[:0:0]
+
+	for _, tok := range toks {
+		if tok.Type != Comment {
+			out = append(out, tok)
+			continue
+		}
+
+		trailing := false
+		if len(out) > 0 {
+			prev := out[len(out)-1]
+			trailing = prev.Type != NewLine && prev.Line == tok.Line
+		}
+		comments = append(comments, trivia{tok: tok, trailing: trailing})
+	}
+
+	return out, comments
+}
+
+// attachComments gives every comment to the statement it annotates. a
+// trailing comment belongs to the last statement that started on or before
+// its line, any other comment to the next statement, or to the last one
+// when none follows
+func (p *parser) attachComments(b *BlockNode) {
+	b.Comments = make([]string, len(b.Blocks))
+
+	for _, c := range p.trivia {
+		i := -1
+		if c.trailing {
+			for j, line := range p.lines {
+				if line <= c.tok.Line {
+					i = j
+				}
+			}
+		}
+		if i < 0 {
+			i = len(b.Blocks) - 1
+			for j, line := range p.lines {
+				if line > c.tok.Line {
+					i = j
+					break
+				}
+			}
+		}
+
+		if b.Comments[i] != "" {
+			b.Comments[i] += "\n"
+		}
+		b.Comments[i] += string(c.tok.Text)
+	}
+}
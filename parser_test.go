@@ -2,6 +2,7 @@ package mathematigo
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -389,12 +390,13 @@ func TestA(t *testing.T) {
 }
 
 func TestUnexpectedChar(t *testing.T) {
-	ex, err := Parse(`1 : 2`)
+	// ':' is a token since objects, see TestParseObject
+	ex, err := Parse(`1 @ 2`)
 
 	var se *ScanErr
 	require.ErrorAs(t, err, &se)
 	require.Zero(t, ex)
-	require.Equal(t, "unexpected character: ':' at position 3", err.Error())
+	require.Equal(t, "unexpected character: '@' at position 3", err.Error())
 }
 
 func TestX(t *testing.T) {
@@ -665,3 +667,511 @@ func TestParse_FactorialAndBlock(t *testing.T) {
 		ex,
 	)
 }
+
+func TestParseAssignment(t *testing.T) {
+	ex, err := Parse("total = price * qty")
+	require.NoError(t, err)
+
+	assert.Equal(t, NewAssignmentNode(NewSymbolNode("total"), NewOperatorNode("*", OperatorFnMultiply, NewSymbolNode("price"), NewSymbolNode("qty"))), ex)
+	assert.Equal(t, "total = price * qty", ex.String())
+}
+
+func TestParseAssignmentBindsLoosest(t *testing.T) {
+	ex, err := Parse("x = a | b == c")
+	require.NoError(t, err)
+
+	assign, ok := ex.(*AssignmentNode)
+	require.True(t, ok)
+	assert.Equal(t, OperatorFnBitOr, assign.Value.(*OperatorNode).Fn)
+
+	// right associative
+	ex, err = Parse("a = b = 1")
+	require.NoError(t, err)
+	assert.Equal(t, NewAssignmentNode(NewSymbolNode("a"), NewAssignmentNode(NewSymbolNode("b"), NewFloatNode(1))), ex)
+}
+
+func TestParseAssignmentErrors(t *testing.T) {
+	for _, expr := range []string{"2 = 3", "a + b = 3", "x =", "(x) = 1"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestParseFunctionAssignment(t *testing.T) {
+	ex, err := Parse("area(w, h) = w * h")
+	require.NoError(t, err)
+
+	expected := NewFunctionAssignmentNode("area", []string{"w", "h"}, NewOperatorNode("*", OperatorFnMultiply, NewSymbolNode("w"), NewSymbolNode("h")))
+	assert.Equal(t, expected, ex)
+	assert.Equal(t, "area(w, h) = w * h", ex.String())
+
+	ex, err = Parse("two() = 2")
+	require.NoError(t, err)
+	assert.Equal(t, NewFunctionAssignmentNode("two", []string{}, NewFloatNode(2)), ex)
+
+	for _, expr := range []string{"f(1) = 2", "f(x + 1) = x", "f(x) ="} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestParseArray(t *testing.T) {
+	ex, err := Parse("[1, x, 2 + 3]")
+	require.NoError(t, err)
+	assert.Equal(t, NewArrayNode(NewFloatNode(1), NewSymbolNode("x"), NewOperatorNode("+", OperatorFnAdd, NewFloatNode(2), NewFloatNode(3))), ex)
+
+	ex, err = Parse("[]")
+	require.NoError(t, err)
+	assert.Equal(t, &ArrayNode{}, ex)
+}
+
+func TestParseMatrixRows(t *testing.T) {
+	expected := NewArrayNode(
+		NewArrayNode(NewFloatNode(1), NewFloatNode(2)),
+		NewArrayNode(NewFloatNode(3), NewFloatNode(4)),
+	)
+
+	for _, expr := range []string{"[1, 2; 3, 4]", "[[1, 2], [3, 4]]", "[\n  1, 2;\n  3, 4\n]", "[[1,\n2], [3, 4]\n]"} {
+		ex, err := Parse(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, ex, expr)
+		assert.Equal(t, "[[1, 2], [3, 4]]", ex.String())
+	}
+}
+
+func TestParseArrayErrors(t *testing.T) {
+	for _, expr := range []string{"[1, 2", "[1, 2)", "[1,, 2]", "[1; ]", "]"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+
+	_, err := Parse("[1, 2")
+	require.ErrorIs(t, err, ErrUnendedArray)
+}
+
+func TestParseAccessors(t *testing.T) {
+	ex, err := Parse("order.customer.tier")
+	require.NoError(t, err)
+	assert.Equal(t, NewAccessorNode(NewAccessorNode(NewSymbolNode("order"), NewPropertyIndexNode("customer")), NewPropertyIndexNode("tier")), ex)
+	assert.Equal(t, "order.customer.tier", ex.String())
+
+	ex, err = Parse("m[2, i + 1]")
+	require.NoError(t, err)
+	assert.Equal(t, NewAccessorNode(NewSymbolNode("m"), NewIndexNode(NewFloatNode(2), NewOperatorNode("+", OperatorFnAdd, NewSymbolNode("i"), NewFloatNode(1)))), ex)
+	assert.Equal(t, "m[2, i + 1]", ex.String())
+
+	ex, err = Parse(`obj["key"].items[1] * 2`)
+	require.NoError(t, err)
+	assert.Equal(t, `obj["key"].items[1] * 2`, ex.String())
+
+	// indexing binds tighter than every operator
+	ex, err = Parse("2 ^ a.b!")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode("^", OperatorFnPower, NewFloatNode(2), NewOperatorNode("!", OperatorFnFactorial, NewAccessorNode(NewSymbolNode("a"), NewPropertyIndexNode("b")))), ex)
+}
+
+func TestParseAccessorErrors(t *testing.T) {
+	for _, expr := range []string{"a.", "a.+", "a[]", "a[1", "a[1; 2]"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+
+	_, err := Parse("a[1")
+	require.ErrorIs(t, err, ErrUnendedIndex)
+}
+
+func TestParseObject(t *testing.T) {
+	ex, err := Parse(`{name: "a", "max limit": 10, limits: {min: 1, max: x + 1}}`)
+	require.NoError(t, err)
+
+	expected := NewObjectNode(
+		ObjectProperty{Key: "name", Value: NewConstantNode("a")},
+		ObjectProperty{Key: "max limit", Value: NewFloatNode(10)},
+		ObjectProperty{Key: "limits", Value: NewObjectNode(
+			ObjectProperty{Key: "min", Value: NewFloatNode(1)},
+			ObjectProperty{Key: "max", Value: NewOperatorNode("+", OperatorFnAdd, NewSymbolNode("x"), NewFloatNode(1))},
+		)},
+	)
+	assert.Equal(t, expected, ex)
+	assert.Equal(t, `{"name": "a", "max limit": 10, "limits": {"min": 1, "max": x + 1}}`, ex.String())
+
+	// String parses back to an equal tree
+	again, err := Parse(ex.String())
+	require.NoError(t, err)
+	assert.True(t, expected.Equal(again))
+
+	ex, err = Parse("{\n  a: 1,\n  b: 2\n}.b")
+	require.NoError(t, err)
+	assert.Equal(t, NewAccessorNode(NewObjectNode(ObjectProperty{Key: "a", Value: NewFloatNode(1)}, ObjectProperty{Key: "b", Value: NewFloatNode(2)}), NewPropertyIndexNode("b")), ex)
+
+	ex, err = Parse("{}")
+	require.NoError(t, err)
+	assert.Equal(t, &ObjectNode{}, ex)
+
+	// a repeated key keeps its place
+	ex, err = Parse("{a: 1, b: 2, a: 3}")
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\": 3, \"b\": 2}", ex.String())
+}
+
+func TestParseObjectErrors(t *testing.T) {
+	for _, expr := range []string{"{a: 1", "{a 1}", "{1: 2}", "{a: 1,}", "{a: 1; b: 2}"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+
+	_, err := Parse("{a: 1")
+	require.ErrorIs(t, err, ErrUnendedObject)
+}
+
+func TestParseRange(t *testing.T) {
+	ex, err := Parse("1:n + 1")
+	require.NoError(t, err)
+	assert.Equal(t, NewRangeNode(NewFloatNode(1), NewOperatorNode("+", OperatorFnAdd, NewSymbolNode("n"), NewFloatNode(1)), nil), ex)
+	assert.Equal(t, "1:n + 1", ex.String())
+
+	ex, err = Parse("0:0.5:5")
+	require.NoError(t, err)
+	assert.Equal(t, NewRangeNode(NewFloatNode(0), NewFloatNode(5), NewFloatNode(0.5)), ex)
+	assert.Equal(t, "0:0.5:5", ex.String())
+
+	// ranges bind looser than arithmetic and tighter than comparisons
+	ex, err = Parse("a < 1:2")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode("<", OperatorFnLt, NewSymbolNode("a"), NewRangeNode(NewFloatNode(1), NewFloatNode(2), nil)), ex)
+
+	ex, err = Parse("sum(1:n)")
+	require.NoError(t, err)
+	assert.Equal(t, NewFunctionNode("sum", NewRangeNode(NewFloatNode(1), NewSymbolNode("n"), nil)), ex)
+
+	for _, expr := range []string{"1:", "1:2:", ":2", "1:2:3:4"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestParseConditional(t *testing.T) {
+	ex, err := Parse(`tier == "gold" ? price * 0.9 : price`)
+	require.NoError(t, err)
+	expected := NewConditionalNode(
+		NewOperatorNode("==", OperatorFnEqual, NewSymbolNode("tier"), NewConstantNode("gold")),
+		NewOperatorNode("*", OperatorFnMultiply, NewSymbolNode("price"), NewFloatNode(0.9)),
+		NewSymbolNode("price"),
+	)
+	assert.Equal(t, expected, ex)
+	assert.Equal(t, `tier == "gold" ? price * 0.9 : price`, ex.String())
+
+	// right associative
+	ex, err = Parse("a ? 1 : b ? 2 : 3")
+	require.NoError(t, err)
+	assert.Equal(t, NewConditionalNode(NewSymbolNode("a"), NewFloatNode(1), NewConditionalNode(NewSymbolNode("b"), NewFloatNode(2), NewFloatNode(3))), ex)
+
+	ex, err = Parse("a ? b ? 1 : 2 : 3")
+	require.NoError(t, err)
+	assert.Equal(t, NewConditionalNode(NewSymbolNode("a"), NewConditionalNode(NewSymbolNode("b"), NewFloatNode(1), NewFloatNode(2)), NewFloatNode(3)), ex)
+
+	// looser than everything but assignment
+	ex, err = Parse("x = a | b ? 1 : 2")
+	require.NoError(t, err)
+	assert.Equal(t, NewAssignmentNode(NewSymbolNode("x"), NewConditionalNode(NewOperatorNode("|", OperatorFnBitOr, NewSymbolNode("a"), NewSymbolNode("b")), NewFloatNode(1), NewFloatNode(2))), ex)
+
+	// a ":" in the true branch ends it unless it is nested
+	ex, err = Parse("a ? (1:2) : 3:4")
+	require.NoError(t, err)
+	assert.Equal(t, NewConditionalNode(
+		NewSymbolNode("a"),
+		NewParenthesisNode(NewRangeNode(NewFloatNode(1), NewFloatNode(2), nil)),
+		NewRangeNode(NewFloatNode(3), NewFloatNode(4), nil),
+	), ex)
+
+	ex, err = Parse("a ? f(1:2) : 0")
+	require.NoError(t, err)
+	assert.Equal(t, NewConditionalNode(NewSymbolNode("a"), NewFunctionNode("f", NewRangeNode(NewFloatNode(1), NewFloatNode(2), nil)), NewFloatNode(0)), ex)
+
+	for _, expr := range []string{"a ?", "a ? 1", "a ? 1 :", "a ? 1 2", "? 1 : 2"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestParseLogicalOperators(t *testing.T) {
+	ex, err := Parse("a > 1 and b < 2")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode("and", OperatorFnAnd,
+		NewOperatorNode(">", OperatorFnGt, NewSymbolNode("a"), NewFloatNode(1)),
+		NewOperatorNode("<", OperatorFnLt, NewSymbolNode("b"), NewFloatNode(2)),
+	), ex)
+	assert.Equal(t, "a > 1 and b < 2", ex.String())
+
+	// or < xor < and < |, like in mathjs
+	ex, err = Parse("a or b xor c and d | e")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode("or", OperatorFnOr,
+		NewSymbolNode("a"),
+		NewOperatorNode("xor", OperatorFnXor,
+			NewSymbolNode("b"),
+			NewOperatorNode("and", OperatorFnAnd,
+				NewSymbolNode("c"),
+				NewOperatorNode("|", OperatorFnBitOr, NewSymbolNode("d"), NewSymbolNode("e")),
+			),
+		),
+	), ex)
+
+	ex, err = Parse("a and b and c")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode("and", OperatorFnAnd, NewOperatorNode("and", OperatorFnAnd, NewSymbolNode("a"), NewSymbolNode("b")), NewSymbolNode("c")), ex)
+
+	// not is unary and binds tighter than comparisons
+	ex, err = Parse("not a == b")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode("==", OperatorFnEqual, NewOperatorNode("not", OperatorFnNot, NewSymbolNode("a")), NewSymbolNode("b")), ex)
+	assert.Equal(t, "not a == b", ex.String())
+
+	ex, err = Parse("a or b ? 1 : 2")
+	require.NoError(t, err)
+	assert.Equal(t, NewConditionalNode(NewOperatorNode("or", OperatorFnOr, NewSymbolNode("a"), NewSymbolNode("b")), NewFloatNode(1), NewFloatNode(2)), ex)
+
+	// the keywords are not symbols
+	for _, expr := range []string{"and", "a and", "or = 1", "2 xor", "not", "f(and)", "and(1, 2)", "x = not"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestParseBitwiseOperators(t *testing.T) {
+	// | < ^| < & < comparisons < shifts < conversion, like in mathjs
+	ex, err := Parse("a | b ^| c & d == e << f")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode("|", OperatorFnBitOr,
+		NewSymbolNode("a"),
+		NewOperatorNode("^|", OperatorFnBitXor,
+			NewSymbolNode("b"),
+			NewOperatorNode("&", OperatorFnBitAnd,
+				NewSymbolNode("c"),
+				NewOperatorNode("==", OperatorFnEqual,
+					NewSymbolNode("d"),
+					NewOperatorNode("<<", OperatorFnLeftShift, NewSymbolNode("e"), NewSymbolNode("f")),
+				),
+			),
+		),
+	), ex)
+	assert.Equal(t, "a | b ^| c & d == e << f", ex.String())
+
+	ex, err = Parse("flags >> 2 >>> n + 1")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode(">>>", OperatorFnRightLogShift,
+		NewOperatorNode(">>", OperatorFnRightArithShift, NewSymbolNode("flags"), NewFloatNode(2)),
+		NewOperatorNode("+", OperatorFnAdd, NewSymbolNode("n"), NewFloatNode(1)),
+	), ex)
+
+	ex, err = Parse("~a & 2^3")
+	require.NoError(t, err)
+	assert.Equal(t, NewOperatorNode("&", OperatorFnBitAnd,
+		NewOperatorNode("~", OperatorFnBitNot, NewSymbolNode("a")),
+		NewOperatorNode("^", OperatorFnPower, NewFloatNode(2), NewFloatNode(3)),
+	), ex)
+	assert.Equal(t, "~a & 2 ^ 3", ex.String())
+
+	for _, expr := range []string{"a <<", ">> 1", "1 ^| ", "~"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestParseSemicolons(t *testing.T) {
+	ex, err := Parse("a = 1; b = 2")
+	require.NoError(t, err)
+	assert.Equal(t, &BlockNode{
+		Blocks: []MathNode{
+			NewAssignmentNode(NewSymbolNode("a"), NewFloatNode(1)),
+			NewAssignmentNode(NewSymbolNode("b"), NewFloatNode(2)),
+		},
+		Hidden: []bool{true, false},
+	}, ex)
+	assert.Equal(t, "a = 1;\nb = 2", ex.String())
+
+	// newlines and semicolons mix, a statement is only hidden by its own ";"
+	ex, err = Parse("a;\nb\nc;")
+	require.NoError(t, err)
+	assert.Equal(t, &BlockNode{
+		Blocks: []MathNode{NewSymbolNode("a"), NewSymbolNode("b"), NewSymbolNode("c")},
+		Hidden: []bool{true, false, true},
+	}, ex)
+
+	// a single hidden statement stays in a block to keep its flag
+	ex, err = Parse("x = 1;")
+	require.NoError(t, err)
+	assert.Equal(t, &BlockNode{Blocks: []MathNode{NewAssignmentNode(NewSymbolNode("x"), NewFloatNode(1))}, Hidden: []bool{true}}, ex)
+
+	// empty statements are skipped
+	ex, err = Parse(";;a;; b")
+	require.NoError(t, err)
+	assert.Equal(t, &BlockNode{Blocks: []MathNode{NewSymbolNode("a"), NewSymbolNode("b")}, Hidden: []bool{true, false}}, ex)
+
+	// inside brackets a semicolon still separates rows
+	ex, err = Parse("[1; 2]; 3")
+	require.NoError(t, err)
+	assert.Equal(t, &BlockNode{
+		Blocks: []MathNode{
+			NewArrayNode(NewArrayNode(NewFloatNode(1)), NewArrayNode(NewFloatNode(2))),
+			NewFloatNode(3),
+		},
+		Hidden: []bool{true, false},
+	}, ex)
+
+	assert.False(t, ex.Equal(NewBlockNode(ex.(*BlockNode).Blocks...)))
+
+	for _, expr := range []string{";", ";\n;", "f(a; b)", "(1; 2)"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	src := "# discount rules\nrate = 0.1 # ten percent\n\nprice * (1 - rate); # net\n# no more rules"
+
+	ex, err := Parse(src)
+	require.NoError(t, err)
+	assert.Equal(t, &BlockNode{
+		Blocks: []MathNode{
+			NewAssignmentNode(NewSymbolNode("rate"), NewFloatNode(0.1)),
+			NewOperatorNode("*", OperatorFnMultiply, NewSymbolNode("price"), NewParenthesisNode(NewOperatorNode("-", OperatorFnSubtract, NewFloatNode(1), NewSymbolNode("rate")))),
+		},
+		Hidden: []bool{false, true},
+	}, ex)
+
+	ex, err = Parse(src, ParseComments())
+	require.NoError(t, err)
+	block, ok := ex.(*BlockNode)
+	require.True(t, ok)
+	assert.Equal(t, []string{"# discount rules\n# ten percent", "# net\n# no more rules"}, block.Comments)
+	assert.Equal(t, "# net\n# no more rules", block.Comment(1))
+
+	// comments do not change the tree
+	assert.True(t, block.Equal(&BlockNode{Blocks: block.Blocks, Hidden: block.Hidden}))
+
+	// a single statement is in a block as well, a comment inside a
+	// statement belongs to it
+	ex, err = Parse("a and # first part\n  b", ParseComments())
+	require.NoError(t, err)
+	assert.Equal(t, &BlockNode{
+		Blocks:   []MathNode{NewOperatorNode("and", OperatorFnAnd, NewSymbolNode("a"), NewSymbolNode("b"))},
+		Comments: []string{"# first part"},
+	}, ex)
+
+	ex, err = Parse("a = 1; b = 2 # b", ParseComments())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "# b"}, ex.(*BlockNode).Comments)
+
+	// the root is a block whether or not there are comments
+	ex, err = Parse("a + b", ParseComments())
+	require.NoError(t, err)
+	assert.Equal(t, &BlockNode{
+		Blocks:   []MathNode{NewOperatorNode("+", OperatorFnAdd, NewSymbolNode("a"), NewSymbolNode("b"))},
+		Comments: []string{""},
+	}, ex)
+	v, err := NewEvaluator().Evaluate(ex, MapScope{"a": 1.0, "b": 2.0})
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, v)
+
+	for _, expr := range []string{"# only a comment", "#"} {
+		_, err := Parse(expr, ParseComments())
+		require.ErrorIs(t, err, ErrEmptyExpression, expr)
+		_, err = Parse(expr)
+		require.ErrorIs(t, err, ErrEmptyExpression, expr)
+	}
+}
+
+func TestParseRadixIntegers(t *testing.T) {
+	cases := []struct {
+		src      string
+		expected *BigIntNode
+		str      string
+	}{
+		{"0x1F", &BigIntNode{value: big.NewInt(31), radix: 16}, "0x1F"},
+		{"0xff", &BigIntNode{value: big.NewInt(255), radix: 16}, "0xFF"},
+		{"0o17", &BigIntNode{value: big.NewInt(15), radix: 8}, "0o17"},
+		{"0b1011", &BigIntNode{value: big.NewInt(11), radix: 2}, "0b1011"},
+		{"0xFFi8", &BigIntNode{value: big.NewInt(-1), radix: 16, wordSize: 8}, "0xFFi8"},
+		{"0x7Fi8", &BigIntNode{value: big.NewInt(127), radix: 16, wordSize: 8}, "0x7Fi8"},
+		{"0b100i3", &BigIntNode{value: big.NewInt(-4), radix: 2, wordSize: 3}, "0b100i3"},
+		{"0xFFFFFFFFFFFFFFFFi64", &BigIntNode{value: big.NewInt(-1), radix: 16, wordSize: 64}, "0xFFFFFFFFFFFFFFFFi64"},
+		{"0x7FFFFFFFFFFFFFFF", &BigIntNode{value: big.NewInt(1<<63 - 1), radix: 16}, "0x7FFFFFFFFFFFFFFF"},
+	}
+
+	for _, c := range cases {
+		ex, err := Parse(c.src)
+		require.NoError(t, err, c.src)
+		assert.Equal(t, c.expected, ex, c.src)
+		assert.Equal(t, c.str, ex.String(), c.src)
+
+		// the number type does not apply to them
+		ex, err = Parse(c.src, ParseNumbers(NumberFraction))
+		require.NoError(t, err, c.src)
+		assert.Equal(t, c.expected, ex, c.src)
+	}
+
+	assert.Equal(t, "-0x10", (&BigIntNode{value: big.NewInt(-16), radix: 16}).String())
+	assert.Equal(t, "16", NewIntNode(16).String())
+	assert.Equal(t, "16", NewBigIntNode(big.NewInt(16)).String())
+
+	// values are compared, whatever the radix or node
+	hex, err := Parse("0x10")
+	require.NoError(t, err)
+	assert.True(t, NewIntNode(16).Equal(hex))
+	assert.True(t, hex.Equal(NewIntNode(16)))
+	assert.True(t, hex.Equal(NewBigIntNode(big.NewInt(16))))
+	assert.False(t, hex.Equal(NewIntNode(17)))
+
+	ex, err := Parse("flags & 0b100 == 0x4")
+	require.NoError(t, err)
+	assert.Equal(t, "flags & 0b100 == 0x4", ex.String())
+
+	// past int64 they keep their value in a big.Int
+	ex, err = Parse("0xFFFFFFFFFFFFFFFF")
+	require.NoError(t, err)
+	require.IsType(t, &BigIntNode{}, ex)
+	n := ex.(*BigIntNode)
+	assert.Equal(t, "18446744073709551615", n.BigInt().String())
+	assert.Equal(t, "0xFFFFFFFFFFFFFFFF", ex.String())
+	assert.Equal(t, 16, n.Radix())
+	assert.Equal(t, 0, n.WordSize())
+	_, ok := n.Int64()
+	assert.False(t, ok)
+
+	// the accessors cannot change the node
+	n.BigInt().SetInt64(1)
+	assert.Equal(t, "18446744073709551615", n.BigInt().String())
+	v, ok := NewBigIntNode(big.NewInt(-5)).Int64()
+	assert.True(t, ok)
+	assert.Equal(t, int64(-5), v)
+
+	for _, src := range []string{"0x100i8", "0b1.1", "0b0.", "0x10000000000000000i64", "0x1i0", "0x1i65"} {
+		_, err := Parse(src)
+		require.ErrorIs(t, err, ErrInvalidSyntax, src)
+	}
+}
+
+func TestParseStringEscapes(t *testing.T) {
+	ex, err := Parse(`concat("say \"hi\"\n", 'it\'s é')`)
+	require.NoError(t, err)
+
+	fn := ex.(*FunctionNode)
+	assert.Equal(t, NewConstantNode("say \"hi\"\n"), fn.Args[0])
+	assert.Equal(t, NewConstantNode("it's é"), fn.Args[1])
+	assert.Equal(t, `concat("say \"hi\"\n", "it's é")`, ex.String())
+
+	// String reads back to the same node
+	again, err := Parse(ex.String())
+	require.NoError(t, err)
+	assert.True(t, ex.Equal(again))
+
+	assert.Equal(t, `"a\\b\u0001"`, NewConstantNode("a\\b\x01").String())
+
+	ex, err = Parse(`{"a\"b": 1}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a\"b": 1}`, ex.String())
+
+	_, err = Parse(`"\q"`)
+	require.ErrorIs(t, err, ErrInvalidSyntax)
+}
@@ -0,0 +1,556 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid function signature")
+	ErrArity            = errors.New("wrong number of arguments")
+	ErrArgumentType     = errors.New("unexpected argument type")
+)
+
+var (
+	errorType    = reflect.TypeFor[error]()
+	functionType = reflect.TypeFor[Function]()
+	valueType    = reflect.TypeFor[Value]()
+	bigIntType   = reflect.TypeFor[*big.Int]()
+	bigFloatType = reflect.TypeFor[*big.Float]()
+	ratType      = reflect.TypeFor[*big.Rat]()
+	unitType     = reflect.TypeFor[*Unit]()
+)
+
+// conversion costs used to pick between overloads, lower wins
+const (
+	costExact = iota
+	costConvert
+	costLossy
+	costAny
+)
+
+// overload is one registered Go func
+type overload struct {
+	params []reflect.Type
+	// variadic is the element type of a trailing ...T parameter, or nil
+	variadic reflect.Type
+	invoke   func(args []Value) (Value, error)
+}
+
+func (o *overload) accepts(n int) bool {
+	if o.variadic != nil {
+		return n >= len(o.params)
+	}
+	return n == len(o.params)
+}
+
+func (o *overload) paramType(i int) reflect.Type {
+	if i < len(o.params) {
+		return o.params[i]
+	}
+	return o.variadic
+}
+
+// match scores args against the overload's parameters. bad is the index of
+// the first argument that cannot be converted, or -1
+func (o *overload) match(args []Value) (cost int, bad int) {
+	for i, arg := range args {
+		c, ok := argCost(arg, o.paramType(i))
+		if !ok {
+			return 0, i
+		}
+		cost += c
+	}
+	return cost, -1
+}
+
+type registryEntry struct {
+	overloads []*overload
+	dispatch  Function
+}
+
+// Registry binds names to ordinary Go funcs. signatures are inferred by
+// reflection and a name may have several overloads, picked per call by the
+// types of the arguments. register every function before evaluating; a
+// Registry is not safe for concurrent writes
+type Registry struct {
+	entries map[string]*registryEntry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{entries: map[string]*registryEntry{}}
+}
+
+// Register adds fn as an overload of name. fn must be a func returning a
+// single value, optionally followed by an error. parameters may be any Go
+// type; numbers are converted to the parameter's numeric kind, integer kinds
+// only accept whole numbers
+func (r *Registry) Register(name string, fn any) error {
+	o, err := newOverload(fn)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidSignature, name, err)
+	}
+
+	entry, ok := r.entries[name]
+	if !ok {
+		entry = &registryEntry{}
+		entry.dispatch = func(args ...Value) (Value, error) {
+			return dispatch(name, entry.overloads, args)
+		}
+		r.entries[name] = entry
+	}
+
+	for _, other := range entry.overloads {
+		if sameSignature(o, other) {
+			return fmt.Errorf("%w: %s: conflicting overload", ErrInvalidSignature, name)
+		}
+	}
+
+	entry.overloads = append(entry.overloads, o)
+	return nil
+}
+
+// MustRegister is Register for function sets built at init time
+func (r *Registry) MustRegister(name string, fn any) {
+	if err := r.Register(name, fn); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns a Function that dispatches to name's overloads
+func (r *Registry) Lookup(name string) (Function, bool) {
+	if r == nil {
+		return nil, false
+	}
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil, false
+	}
+	return entry.dispatch, true
+}
+
+func (r *Registry) Call(name string, args ...Value) (Value, error) {
+	fn, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUndefinedFunction, name)
+	}
+	return fn(args...)
+}
+
+// Names lists the registered names in sorted order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newOverload(fn any) (*overload, error) {
+	// Function is called directly, there is nothing to convert
+	switch f := fn.(type) {
+	case Function:
+		return &overload{variadic: valueType, invoke: func(args []Value) (Value, error) { return f(args...) }}, nil
+	case func(...Value) (Value, error):
+		return &overload{variadic: valueType, invoke: func(args []Value) (Value, error) { return f(args...) }}, nil
+	}
+
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func || rv.IsNil() {
+		return nil, fmt.Errorf("expected a func, got %T", fn)
+	}
+
+	t := rv.Type()
+	returnsErr := false
+	switch {
+	case t.NumOut() == 1 && t.Out(0) != errorType:
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+		returnsErr = true
+	default:
+		return nil, fmt.Errorf("%s must return a value, optionally followed by an error", t)
+	}
+
+	o := &overload{}
+	for i := range t.NumIn() {
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			o.variadic = t.In(i).Elem()
+		} else {
+			o.params = append(o.params, t.In(i))
+		}
+	}
+
+	o.invoke = fastInvoke(fn)
+	if o.invoke == nil {
+		o.invoke = func(args []Value) (Value, error) {
+			// numbers passed as BigNumbers take the precision of the
+			// BigNumber arguments
+			prec := bigPrecOf(args...)
+
+			in := make([]reflect.Value, len(args))
+			for i, arg := range args {
+				in[i] = convertArg(arg, o.paramType(i), prec)
+			}
+
+			out := rv.Call(in)
+			if returnsErr && !out[1].IsNil() {
+				return nil, out[1].Interface().(error)
+			}
+			return fromReflect(out[0]), nil
+		}
+	}
+
+	return o, nil
+}
+
+// fastInvoke skips reflection for the most common numeric signatures
+func fastInvoke(fn any) func(args []Value) (Value, error) {
+	switch f := fn.(type) {
+	case func(float64) float64:
+		return func(args []Value) (Value, error) {
+			x, _ := toNumber(args[0])
+			return f(x), nil
+		}
+	case func(float64, float64) float64:
+		return func(args []Value) (Value, error) {
+			x, _ := toNumber(args[0])
+			y, _ := toNumber(args[1])
+			return f(x, y), nil
+		}
+	case func(...float64) float64:
+		return func(args []Value) (Value, error) {
+			xs := make([]float64, len(args))
+			for i, arg := range args {
+				xs[i], _ = toNumber(arg)
+			}
+			return f(xs...), nil
+		}
+	case func(float64) Value:
+		return func(args []Value) (Value, error) {
+			x, _ := toNumber(args[0])
+			return f(x), nil
+		}
+	case func(float64) (float64, error):
+		return func(args []Value) (Value, error) {
+			x, _ := toNumber(args[0])
+			out, err := f(x)
+			if err != nil {
+				return nil, err
+			}
+			return out, nil
+		}
+	}
+	return nil
+}
+
+func sameSignature(a, b *overload) bool {
+	if a.variadic != b.variadic || len(a.params) != len(b.params) {
+		return false
+	}
+	for i := range a.params {
+		if a.params[i] != b.params[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func dispatch(name string, overloads []*overload, args []Value) (Value, error) {
+	var best *overload
+	bestCost := 0
+	// furthest is the overload that matched the most arguments, used to
+	// report the argument that could not be converted
+	var furthest []*overload
+	furthestBad := -1
+
+	for _, o := range overloads {
+		if !o.accepts(len(args)) {
+			continue
+		}
+
+		cost, bad := o.match(args)
+		if bad < 0 {
+			if best == nil || cost < bestCost {
+				best, bestCost = o, cost
+			}
+			continue
+		}
+
+		switch {
+		case bad > furthestBad:
+			furthest, furthestBad = []*overload{o}, bad
+		case bad == furthestBad:
+			furthest = append(furthest, o)
+		}
+	}
+
+	if best != nil {
+		return best.invoke(args)
+	}
+
+	if furthest == nil {
+		return nil, fmt.Errorf("%w: %s expects %s, got %d", ErrArity, name, describeArity(overloads), len(args))
+	}
+
+	expected := make([]string, 0, len(furthest))
+	for _, o := range furthest {
+		expected = appendUnique(expected, reflectTypeName(o.paramType(furthestBad)))
+	}
+
+	return nil, fmt.Errorf(
+		"%w: argument %d of %s: expected %s, got %s",
+		ErrArgumentType, furthestBad+1, name, strings.Join(expected, " or "), typeOf(args[furthestBad]),
+	)
+}
+
+// describeArity lists the argument counts the overloads accept, e.g. "1 or
+// at least 3". counts that a variadic overload already covers are left out
+func describeArity(overloads []*overload) string {
+	minVariadic := -1
+	for _, o := range overloads {
+		if o.variadic != nil && (minVariadic < 0 || len(o.params) < minVariadic) {
+			minVariadic = len(o.params)
+		}
+	}
+
+	var fixed []int
+	for _, o := range overloads {
+		n := len(o.params)
+		if o.variadic == nil && (minVariadic < 0 || n < minVariadic) && !slices.Contains(fixed, n) {
+			fixed = append(fixed, n)
+		}
+	}
+	slices.Sort(fixed)
+
+	counts := make([]string, 0, len(fixed)+1)
+	for _, n := range fixed {
+		counts = append(counts, strconv.Itoa(n))
+	}
+	if minVariadic >= 0 {
+		counts = append(counts, fmt.Sprintf("at least %d", minVariadic))
+	}
+
+	suffix := "arguments"
+	if len(counts) == 1 && (counts[0] == "1" || counts[0] == "at least 1") {
+		suffix = "argument"
+	}
+	return strings.Join(counts, " or ") + " " + suffix
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, x := range list {
+		if x == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return isFloatKind(k) || isIntKind(k)
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isComplexKind(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// argCost reports whether v can be passed as a t and how specific the match is
+func argCost(v Value, t reflect.Type) (int, bool) {
+	if t == bigFloatType {
+		switch x := v.(type) {
+		case *big.Float:
+			return costExact, true
+		case float64:
+			// a BigNumber cannot hold NaN
+			return costConvert, !math.IsNaN(x)
+		case *big.Rat, bool, nil:
+			// Fractions only become BigNumbers when nothing exact matches
+			return costLossy, true
+		case *big.Int:
+			// bigints prefer the float64 overloads, as in mathjs
+			return costLossy, true
+		}
+		return 0, false
+	}
+
+	if t == ratType {
+		switch x := v.(type) {
+		case *big.Rat:
+			return costExact, true
+		case float64:
+			return costConvert, !math.IsNaN(x) && !math.IsInf(x, 0)
+		case *big.Int, bool, nil:
+			return costLossy, true
+		}
+		return 0, false
+	}
+
+	if t == unitType || t == bigIntType {
+		// unlike other pointers, Unit and bigint parameters never receive
+		// null or other types
+		return costExact, v != nil && reflect.TypeOf(v) == t
+	}
+
+	if t.Kind() == reflect.Interface {
+		if v == nil || reflect.TypeOf(v).Implements(t) {
+			if t.NumMethod() == 0 {
+				return costAny, true
+			}
+			return costConvert, true
+		}
+		return 0, false
+	}
+
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Func:
+			return costConvert, true
+		}
+		// like mathjs, null converts to the number 0
+		return costLossy, isNumberKind(t.Kind()) || isComplexKind(t.Kind())
+	}
+
+	vt := reflect.TypeOf(v)
+	if vt == t {
+		return costExact, true
+	}
+	if vt.AssignableTo(t) {
+		return costConvert, true
+	}
+
+	switch x := v.(type) {
+	case float64:
+		switch {
+		case isFloatKind(t.Kind()), isComplexKind(t.Kind()):
+			return costConvert, true
+		case isIntKind(t.Kind()):
+			// only whole numbers that survive the round trip through t
+			iv := reflect.ValueOf(x).Convert(t)
+			return costConvert, iv.Convert(vt).Float() == x
+		}
+	case *big.Float:
+		switch {
+		case isFloatKind(t.Kind()), isComplexKind(t.Kind()):
+			return costLossy, true
+		case isIntKind(t.Kind()):
+			// same as for float64, but the check happens on the exact value
+			i, acc := x.Int64()
+			return costConvert, acc == big.Exact && fitsInt(i, t)
+		}
+	case *big.Rat:
+		switch {
+		case isFloatKind(t.Kind()), isComplexKind(t.Kind()):
+			return costLossy, true
+		case isIntKind(t.Kind()):
+			return costConvert, x.IsInt() && x.Num().IsInt64() && fitsInt(x.Num().Int64(), t)
+		}
+	case *big.Int:
+		switch {
+		case isFloatKind(t.Kind()), isComplexKind(t.Kind()):
+			return costConvert, true
+		case isIntKind(t.Kind()):
+			return costConvert, x.IsInt64() && fitsInt(x.Int64(), t)
+		}
+	case bool:
+		if t.Kind() == reflect.Bool {
+			return costConvert, true
+		}
+		return costLossy, isNumberKind(t.Kind()) || isComplexKind(t.Kind())
+	case complex128:
+		// complex numbers never lose their imaginary part implicitly
+		return costConvert, isComplexKind(t.Kind())
+	case string:
+		return costConvert, t.Kind() == reflect.String
+	}
+
+	return 0, false
+}
+
+// fitsInt reports whether i survives the round trip through the integer type t
+func fitsInt(i int64, t reflect.Type) bool {
+	if i < 0 && !reflect.Zero(t).CanInt() {
+		return false
+	}
+	return reflect.ValueOf(i).Convert(t).Convert(reflect.TypeFor[int64]()).Int() == i
+}
+
+// convertArg converts v to t, argCost must have accepted the pair. prec is
+// the precision of numbers converted to BigNumbers
+func convertArg(v Value, t reflect.Type, prec uint) reflect.Value {
+	if t == bigFloatType {
+		b, _ := toBig(v, prec)
+		return reflect.ValueOf(b)
+	}
+	if t == ratType {
+		r, _ := toRat(v)
+		return reflect.ValueOf(r)
+	}
+
+	if v == nil {
+		return reflect.Zero(t)
+	}
+
+	if isComplexKind(t.Kind()) {
+		z, _ := toComplex(v)
+		return reflect.ValueOf(z).Convert(t)
+	}
+
+	// bigints past 2^53 would lose digits on the way through toNumber
+	if x, ok := v.(*big.Int); ok && isIntKind(t.Kind()) {
+		return reflect.ValueOf(x.Int64()).Convert(t)
+	}
+
+	if t.Kind() != reflect.Interface && isNumberKind(t.Kind()) {
+		if x, ok := toNumber(v); ok {
+			return reflect.ValueOf(x).Convert(t)
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if t.Kind() != reflect.Interface && rv.Type() != t {
+		return rv.Convert(t)
+	}
+	return rv
+}
+
+// reflectTypeName describes a parameter type in the terms of typeOf
+func reflectTypeName(t reflect.Type) string {
+	switch {
+	case t.Kind() == reflect.Interface && t.NumMethod() == 0:
+		return "any"
+	case isIntKind(t.Kind()):
+		return "integer"
+	case isFloatKind(t.Kind()):
+		return "number"
+	case isComplexKind(t.Kind()):
+		return "Complex"
+	case t.Kind() == reflect.Bool:
+		return "boolean"
+	case t.Kind() == reflect.String:
+		return "string"
+	case t == functionType:
+		return "function"
+	default:
+		return typeOf(reflect.Zero(t).Interface())
+	}
+}
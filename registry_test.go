@@ -0,0 +1,169 @@
+package mathematigo
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryInfersSignatures(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register("hypot", math.Hypot))
+	require.NoError(t, r.Register("sum", func(xs ...float64) float64 {
+		out := 0.0
+		for _, x := range xs {
+			out += x
+		}
+		return out
+	}))
+	require.NoError(t, r.Register("repeat", strings.Repeat))
+	require.NoError(t, r.Register("half", func(n int) int { return n / 2 }))
+
+	v, err := r.Call("hypot", 3.0, 4.0)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, v)
+
+	v, err = r.Call("sum")
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, v)
+
+	v, err = r.Call("sum", 1.0, 2.0, true)
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, v)
+
+	v, err = r.Call("repeat", "ab", 3.0)
+	require.NoError(t, err)
+	assert.Equal(t, "ababab", v)
+
+	// integer results come back as numbers
+	v, err = r.Call("half", 7.0)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, v)
+
+	_, err = r.Call("half", 7.5)
+	require.ErrorIs(t, err, ErrArgumentType)
+	assert.Equal(t, "unexpected argument type: argument 1 of half: expected integer, got number", err.Error())
+}
+
+func TestRegistryErrors(t *testing.T) {
+	r := NewRegistry()
+
+	failing := errors.New("boom")
+	require.NoError(t, r.Register("fail", func(x float64) (float64, error) { return 0, failing }))
+	require.NoError(t, r.Register("pair", func(a, b float64) float64 { return a + b }))
+
+	_, err := r.Call("fail", 1.0)
+	require.ErrorIs(t, err, failing)
+
+	_, err = r.Call("pair", 1.0)
+	require.ErrorIs(t, err, ErrArity)
+	assert.Equal(t, "wrong number of arguments: pair expects 2 arguments, got 1", err.Error())
+
+	_, err = r.Call("pair", 1.0, "x")
+	require.ErrorIs(t, err, ErrArgumentType)
+	assert.Equal(t, "unexpected argument type: argument 2 of pair: expected number, got string", err.Error())
+
+	_, err = r.Call("missing")
+	require.ErrorIs(t, err, ErrUndefinedFunction)
+
+	require.ErrorIs(t, r.Register("bad", 1), ErrInvalidSignature)
+	require.ErrorIs(t, r.Register("bad", func(x float64) {}), ErrInvalidSignature)
+	require.ErrorIs(t, r.Register("bad", func(x float64) error { return nil }), ErrInvalidSignature)
+	require.ErrorIs(t, r.Register("pair", func(a, b float64) float64 { return a - b }), ErrInvalidSignature)
+}
+
+func TestRegistryOverloads(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register("describe", func(x float64) string { return "number" }))
+	require.NoError(t, r.Register("describe", func(s string) string { return "string" }))
+	require.NoError(t, r.Register("describe", func(b bool) string { return "boolean" }))
+	require.NoError(t, r.Register("describe", func(a, b any) string { return "pair" }))
+	require.NoError(t, r.Register("describe", func(x float64, rest ...string) string { return "number and strings" }))
+
+	cases := []struct {
+		args     []Value
+		expected string
+	}{
+		{[]Value{1.0}, "number"},
+		{[]Value{"a"}, "string"},
+		// exact matches beat the bool to number conversion
+		{[]Value{true}, "boolean"},
+		{[]Value{1.0, 2.0}, "pair"},
+		{[]Value{1.0, "a"}, "number and strings"},
+		{[]Value{1.0, "a", "b"}, "number and strings"},
+	}
+
+	for _, c := range cases {
+		v, err := r.Call("describe", c.args...)
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, v, "%v", c.args)
+	}
+
+	_, err := r.Call("describe")
+	require.ErrorIs(t, err, ErrArity)
+	assert.Equal(t, "wrong number of arguments: describe expects at least 1 argument, got 0", err.Error())
+
+	_, err = r.Call("describe", 1.0, "a", 2.0)
+	require.ErrorIs(t, err, ErrArgumentType)
+	assert.Equal(t, "unexpected argument type: argument 3 of describe: expected string, got number", err.Error())
+}
+
+func TestRegistryAcceptsFunction(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register("first", Function(func(args ...Value) (Value, error) { return args[0], nil })))
+
+	v, err := r.Call("first", "a", 1.0)
+	require.NoError(t, err)
+	assert.Equal(t, "a", v)
+
+	assert.Equal(t, []string{"first"}, r.Names())
+}
+
+func TestEvaluateWithRegistry(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register("max", math.Max))
+
+	node, err := Parse("max(a, 2) * 2")
+	require.NoError(t, err)
+
+	e := NewEvaluator(WithRegistry(r))
+
+	v, err := e.Evaluate(node, MapScope{"a": 5.0})
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, v)
+
+	p, err := e.Compile(node)
+	require.NoError(t, err)
+	v, err = p.Eval(MapScope{"a": 1.0})
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, v)
+
+	bc, err := CompileBytecode(node)
+	require.NoError(t, err)
+	v, err = e.NewVM(bc).Run(MapScope{"a": 3.0})
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, v)
+
+	_, err = e.Evaluate(node, MapScope{"a": "x"})
+	require.ErrorIs(t, err, ErrArgumentType)
+}
+
+func TestRegistryArityMessage(t *testing.T) {
+	_, err := NewStandardRegistry().Call("max")
+	require.ErrorIs(t, err, ErrArity)
+	assert.Equal(t, "wrong number of arguments: max expects at least 1 argument, got 0", err.Error())
+
+	// fixed counts below the variadic minimum are listed in order
+	r := NewRegistry()
+	r.MustRegister("f", func(a, b, c float64, rest ...float64) float64 { return a })
+	r.MustRegister("f", func(a, b string) string { return a })
+	r.MustRegister("f", func() float64 { return 0 })
+	r.MustRegister("f", func(a, b, c, d string) string { return a })
+	_, err = r.Call("f", 1.0)
+	require.ErrorIs(t, err, ErrArity)
+	assert.Equal(t, "wrong number of arguments: f expects 0 or 2 or at least 3 arguments, got 1", err.Error())
+}
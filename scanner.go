@@ -3,6 +3,9 @@ package mathematigo
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf16"
 )
 
 var ErrEndStringExpected = errors.New("end of string expected")
@@ -40,6 +43,11 @@ type Scanner struct {
 	current int
 	start   int
 	line    int
+
+	// keepComments emits Comment tokens instead of dropping comments
+	keepComments bool
+	// isAlpha tests the characters of identifiers, IsAlpha by default
+	isAlpha func(r rune) bool
 }
 
 func NewScanner(source string) *Scanner {
@@ -54,6 +62,8 @@ func NewScanner(source string) *Scanner {
 		current: 0,
 		start:   0,
 		line:    0,
+
+		isAlpha: IsAlpha,
 	}
 }
 
@@ -88,35 +98,88 @@ func (s *Scanner) peekMany(num int) ([]rune, bool) {
 	return s.source[s.current : s.current+num], true
 }
 
+// string scans the rest of a string literal and decodes its escapes. they
+// are the ones of JSON, plus \' for strings in single quotes
 func (s *Scanner) string(opener rune) ([]rune, error) {
-	for next, isNotEnd := s.peek(); next != opener && isNotEnd; next, isNotEnd = s.peek() {
-		if next == opener {
-			// closer found
-			break
+	out := []rune{}
+	for {
+		if s.isAtEnd() {
+			return nil, fmt.Errorf("%w: (char %d)", ErrEndStringExpected, s.current+1)
 		}
-		if next == '\n' {
+
+		r := s.advance()
+		switch r {
+		case opener:
+			return out, nil
+		case '\\':
+			decoded, err := s.escape()
+			if err != nil {
+				return nil, err
+			}
+			r = decoded
+		case '\n':
 			s.line++
 		}
-
-		s.advance()
+		out = append(out, r)
 	}
+}
 
-	if s.isAtEnd() {
+var stringEscapes = map[rune]rune{
+	'"':  '"',
+	'\'': '\'',
+	'\\': '\\',
+	'/':  '/',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+}
 
-		return nil, fmt.Errorf("%w: (char %d)", ErrEndStringExpected, s.current+1)
+// escape decodes the escape sequence after a backslash. a \u escape of the
+// first half of a UTF-16 surrogate pair takes the second half from the \u
+// escape following it
+func (s *Scanner) escape() (rune, error) {
+	if s.isAtEnd() {
+		return 0, fmt.Errorf("%w: (char %d)", ErrEndStringExpected, s.current+1)
 	}
 
-	// the closer is at closerIdx, so we can slice using
-	// this index since the slice is exclusive end
-	closerIdx := s.current
-
-	// this increments s.current
-	s.advance()
+	r := s.advance()
+	if decoded, ok := stringEscapes[r]; ok {
+		return decoded, nil
+	}
+	if r != 'u' {
+		return 0, fmt.Errorf("%w: invalid escape sequence \\%c (char %d)", ErrInvalidSyntax, r, s.current)
+	}
 
-	afterOpenerIdx := s.start + 1
+	high, err := s.hex4()
+	if err != nil || !utf16.IsSurrogate(high) {
+		return high, err
+	}
 
-	return s.source[afterOpenerIdx:closerIdx], nil
+	next, ok := s.peekMany(6)
+	if ok && next[0] == '\\' && next[1] == 'u' {
+		if low, err := strconv.ParseUint(string(next[2:]), 16, 16); err == nil {
+			if r := utf16.DecodeRune(high, rune(low)); r != unicode.ReplacementChar {
+				s.current += 6
+				return r, nil
+			}
+		}
+	}
+	// half a pair has no rune of its own
+	return unicode.ReplacementChar, nil
+}
 
+// hex4 reads the four hex digits of a \u escape
+func (s *Scanner) hex4() (rune, error) {
+	digits, ok := s.peekMany(4)
+	if ok {
+		if u, err := strconv.ParseUint(string(digits), 16, 16); err == nil {
+			s.current += 4
+			return rune(u), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: \\u must be followed by four hex digits (char %d)", ErrInvalidSyntax, s.current+1)
 }
 
 func isASCIIDigit(r rune) bool {
@@ -127,10 +190,6 @@ func isASCIIAlpha(r rune) bool {
 	return (65 <= r && r <= 90) || (97 <= r && r <= 122)
 }
 
-func isASCIIAlphanumeric(r rune) bool {
-	return isASCIIAlpha(r) || isASCIIDigit(r)
-}
-
 func (s *Scanner) scanToken() error {
 	r := s.advance()
 
@@ -141,6 +200,24 @@ func (s *Scanner) scanToken() error {
 	case '(':
 		s.addToken(NewToken(OpenParen, s.source[s.start:s.current], s.line, nil))
 		return nil
+	case '[':
+		s.addToken(NewToken(OpenBracket, s.source[s.start:s.current], s.line, nil))
+		return nil
+	case ']':
+		s.addToken(NewToken(CloseBracket, s.source[s.start:s.current], s.line, nil))
+		return nil
+	case '{':
+		s.addToken(NewToken(OpenBrace, s.source[s.start:s.current], s.line, nil))
+		return nil
+	case '}':
+		s.addToken(NewToken(CloseBrace, s.source[s.start:s.current], s.line, nil))
+		return nil
+	case ':':
+		s.addToken(NewToken(Colon, s.source[s.start:s.current], s.line, nil))
+		return nil
+	case '?':
+		s.addToken(NewToken(Question, s.source[s.start:s.current], s.line, nil))
+		return nil
 	case '+':
 		s.addToken(NewToken(Plus, s.source[s.start:s.current], s.line, nil))
 		return nil
@@ -169,7 +246,14 @@ func (s *Scanner) scanToken() error {
 		s.addToken(NewToken(Mod, s.source[s.start:s.current], s.line, nil))
 		return nil
 	case '^':
-		s.addToken(NewToken(Caret, s.source[s.start:s.current], s.line, nil))
+		if s.matchNext('|') {
+			s.addToken(NewToken(CaretPipe, s.source[s.start:s.current], s.line, nil))
+		} else {
+			s.addToken(NewToken(Caret, s.source[s.start:s.current], s.line, nil))
+		}
+		return nil
+	case '~':
+		s.addToken(NewToken(Tilde, s.source[s.start:s.current], s.line, nil))
 		return nil
 	case '&':
 		s.addToken(NewToken(Ampersand, s.source[s.start:s.current], s.line, nil))
@@ -186,6 +270,8 @@ func (s *Scanner) scanToken() error {
 	case '<':
 		if s.matchNext('=') {
 			s.addToken(NewToken(Lteq, s.source[s.start:s.current], s.line, nil))
+		} else if s.matchNext('<') {
+			s.addToken(NewToken(LtLt, s.source[s.start:s.current], s.line, nil))
 		} else {
 			s.addToken(NewToken(Lt, s.source[s.start:s.current], s.line, nil))
 		}
@@ -193,6 +279,12 @@ func (s *Scanner) scanToken() error {
 	case '>':
 		if s.matchNext('=') {
 			s.addToken(NewToken(Gteq, s.source[s.start:s.current], s.line, nil))
+		} else if s.matchNext('>') {
+			if s.matchNext('>') {
+				s.addToken(NewToken(GtGtGt, s.source[s.start:s.current], s.line, nil))
+			} else {
+				s.addToken(NewToken(GtGt, s.source[s.start:s.current], s.line, nil))
+			}
 		} else {
 			s.addToken(NewToken(Gt, s.source[s.start:s.current], s.line, nil))
 		}
@@ -220,6 +312,15 @@ func (s *Scanner) scanToken() error {
 		}
 		s.addToken(NewToken(String, s.source[s.start:s.current], s.line, val))
 
+		return nil
+	case '#':
+		// a comment runs to the end of the line
+		for next, ok := s.peek(); ok && next != '\n'; next, ok = s.peek() {
+			s.advance()
+		}
+		if s.keepComments {
+			s.addToken(NewToken(Comment, s.source[s.start:s.current], s.line, nil))
+		}
 		return nil
 	case ' ', '\t', '\r':
 		// do nothing
@@ -240,18 +341,28 @@ func (s *Scanner) scanToken() error {
 		if justZero {
 			next, ok := s.peek()
 
-			if ok && next == 'b' {
-				// must be binary
-				s.advance() // consume 'b'
+			if ok && radixLetter(next) {
+				// must be binary, octal or hexadecimal
+				s.advance() // consume the letter
+				isDigit := radixDigits[next]
 
 				next, ok := s.peek()
 
-				if !ok || (next != '1' && next != '0') {
+				if !ok || !isDigit(next) {
 					return ErrInvalidSyntax
 				}
 
 				s.advance() // at least one num
-				s.advanceTilEndOfNumberBinary()
+				s.advanceTilEndOfNumberRadix(isDigit)
+
+				// a word size suffix, e.g. 0xFFi8
+				if suffix, ok := s.peekMany(2); ok && suffix[0] == 'i' && isASCIIDigit(suffix[1]) {
+					s.advance()
+					for next, ok := s.peek(); ok && isASCIIDigit(next); next, ok = s.peek() {
+						s.advance()
+					}
+				}
+
 				s.addToken(NewToken(Number, s.source[s.start:s.current], s.line, nil))
 			} else {
 				// default case
@@ -300,8 +411,8 @@ func (s *Scanner) scanToken() error {
 
 		return nil
 	default:
-		if isIdentifierChar(r) {
-			for next, ok := s.peek(); ok && isIdentifierChar(next); next, ok = s.peek() {
+		if s.isAlpha(r) {
+			for next, ok := s.peek(); ok && (s.isAlpha(next) || isASCIIDigit(next)); next, ok = s.peek() {
 				s.advance()
 			}
 
@@ -412,8 +523,21 @@ func (s *Scanner) scanScientific() (*Scientific, error) {
 	}
 }
 
-func isIdentifierChar(r rune) bool {
-	return r == '_' || isASCIIAlphanumeric(r)
+// IsAlpha reports whether identifiers may contain r, following the isAlpha
+// of mathjs: latin and greek letters, letter-like symbols such as ℏ, the
+// mathematical alphanumeric symbols such as 𝑥, _ and $. ∞ is accepted as
+// well, so that it can name a constant. see ParseIdentifiers to change it
+func IsAlpha(r rune) bool {
+	switch {
+	case isASCIIAlpha(r), r == '_', r == '$', r == '∞':
+		return true
+	case 0xC0 <= r && r <= 0x2AF, 0x370 <= r && r <= 0x3FF, 0x2100 <= r && r <= 0x214F:
+		return true
+	case 0x1D400 <= r && r <= 0x1D7FF:
+		// mathjs skips the unassigned code points of the block
+		return unicode.In(r, unicode.L, unicode.Nd, unicode.Sm)
+	}
+	return false
 }
 
 // the return type is not useful if canDot=false
@@ -442,10 +566,23 @@ func (s *Scanner) advanceTilEndOfNumber(canDot bool) *int {
 	}
 }
 
-func (s *Scanner) advanceTilEndOfNumberBinary() {
+// radixDigits tells the digits of binary, octal and hexadecimal literals
+// apart by the letter after their leading 0
+var radixDigits = map[rune]func(rune) bool{
+	'b': func(r rune) bool { return r == '0' || r == '1' },
+	'o': func(r rune) bool { return '0' <= r && r <= '7' },
+	'x': func(r rune) bool { return isASCIIDigit(r) || ('a' <= r && r <= 'f') || ('A' <= r && r <= 'F') },
+}
+
+func radixLetter(r rune) bool {
+	_, ok := radixDigits[r]
+	return ok
+}
+
+func (s *Scanner) advanceTilEndOfNumberRadix(isDigit func(rune) bool) {
 	canDot := true
 
-	for next, ok := s.peek(); ok && ((next == '1' || next == '0') || (canDot && next == '.')); next, ok = s.peek() {
+	for next, ok := s.peek(); ok && (isDigit(next) || (canDot && next == '.')); next, ok = s.peek() {
 		// if found dot, flip bool
 		if next == '.' {
 			canDot = false
@@ -112,7 +112,8 @@ func TestScanBangEq(t *testing.T) {
 }
 
 func TestLongerScanTokens(t *testing.T) {
-	s := NewScanner("!!=)()<<=<>")
+	// the space keeps < and <= apart, << is a shift
+	s := NewScanner("!!=)()< <=<>")
 
 	tokens, err := s.scanTokens()
 	require.NoError(t, err)
@@ -891,3 +892,181 @@ func TestEmptyExpressionScansNilTokens(t *testing.T) {
 		require.Nil(t, toks)
 	}
 }
+
+func TestScanBrackets(t *testing.T) {
+	s := NewScanner("[]")
+
+	tokens, err := s.scanTokens()
+	require.NoError(t, err)
+
+	assert.Equal(t, []Token{
+		{
+			Type: OpenBracket,
+			Text: []rune("["),
+			Line: 0,
+		},
+		{
+			Type: CloseBracket,
+			Text: []rune("]"),
+			Line: 0,
+		},
+	}, tokens)
+}
+
+func TestScanBitwiseOperators(t *testing.T) {
+	s := NewScanner("<<>>>>>^|^~")
+
+	tokens, err := s.scanTokens()
+	require.NoError(t, err)
+
+	assert.Equal(t, []Token{
+		{
+			Type: LtLt,
+			Text: []rune("<<"),
+			Line: 0,
+		},
+		{
+			Type: GtGtGt,
+			Text: []rune(">>>"),
+			Line: 0,
+		},
+		{
+			Type: GtGt,
+			Text: []rune(">>"),
+			Line: 0,
+		},
+		{
+			Type: CaretPipe,
+			Text: []rune("^|"),
+			Line: 0,
+		},
+		{
+			Type: Caret,
+			Text: []rune("^"),
+			Line: 0,
+		},
+		{
+			Type: Tilde,
+			Text: []rune("~"),
+			Line: 0,
+		},
+	}, tokens)
+}
+
+func TestScanComments(t *testing.T) {
+	s := NewScanner("a # note \"x\"\n\"#b\"#")
+
+	tokens, err := s.scanTokens()
+	require.NoError(t, err)
+
+	assert.Equal(t, []Token{
+		{
+			Type: Ident,
+			Text: []rune("a"),
+			Line: 0,
+		},
+		{
+			Type: NewLine,
+			Line: 0,
+		},
+		{
+			Type:    String,
+			Text:    []rune("\"#b\""),
+			Literal: []rune("#b"),
+			Line:    1,
+		},
+	}, tokens)
+
+	s = NewScanner("a # note\n#")
+	s.keepComments = true
+
+	tokens, err = s.scanTokens()
+	require.NoError(t, err)
+
+	assert.Equal(t, []Token{
+		{
+			Type: Ident,
+			Text: []rune("a"),
+			Line: 0,
+		},
+		{
+			Type: Comment,
+			Text: []rune("# note"),
+			Line: 0,
+		},
+		{
+			Type: NewLine,
+			Line: 0,
+		},
+		{
+			Type: Comment,
+			Text: []rune("#"),
+			Line: 1,
+		},
+	}, tokens)
+}
+
+func TestScanRadixNumbers(t *testing.T) {
+	for _, src := range []string{"0x1F", "0xff", "0o17", "0b1011", "0xFFi8", "0b1i64"} {
+		tokens, err := NewScanner(src).scanTokens()
+		require.NoError(t, err, src)
+		assert.Equal(t, []Token{{Type: Number, Text: []rune(src)}}, tokens, src)
+	}
+
+	// a word size needs digits, otherwise the i is the imaginary unit
+	tokens, err := NewScanner("0x1i").scanTokens()
+	require.NoError(t, err)
+	assert.Equal(t, []Token{{Type: Number, Text: []rune("0x1")}, {Type: Ident, Text: []rune("i")}}, tokens)
+
+	for _, src := range []string{"0x", "0xg", "0o8", "0b2"} {
+		_, err := NewScanner(src).scanTokens()
+		require.Error(t, err, src)
+	}
+}
+
+func TestScanUnicodeIdentifiers(t *testing.T) {
+	for _, src := range []string{"π", "α2", "$total", "Größe", "ℏ", "𝑥", "∞", "μ_β"} {
+		tokens, err := NewScanner(src).scanTokens()
+		require.NoError(t, err, src)
+		assert.Equal(t, []Token{{Type: Ident, Text: []rune(src)}}, tokens, src)
+	}
+
+	// letters outside the mathjs ranges are rejected by default
+	_, err := NewScanner("数量").scanTokens()
+	var scanErr *ScanErr
+	require.ErrorAs(t, err, &scanErr)
+	assert.Equal(t, "unexpected character: '数' at position 1", err.Error())
+
+	s := NewScanner("数量 * 2")
+	s.isAlpha = unicode.IsLetter
+	tokens, err := s.scanTokens()
+	require.NoError(t, err)
+	assert.Equal(t, Token{Type: Ident, Text: []rune("数量")}, tokens[0])
+}
+
+func TestScanStringEscapes(t *testing.T) {
+	cases := map[string]string{
+		`"say \"hi\""`:      `say "hi"`,
+		`'it\'s'`:           `it's`,
+		`"a\\b"`:            `a\b`,
+		`"1\n2\t3\r\b\f\/"`: "1\n2\t3\r\b\f/",
+		`"été"`:             "été",
+		`"😀"`:               "😀",
+		`"\ud83d!"`:         "�!",
+		`"\ud83dA"`:         "�A",
+		`'"'`:               `"`,
+	}
+
+	for src, expected := range cases {
+		tokens, err := NewScanner(src).scanTokens()
+		require.NoError(t, err, src)
+		require.Len(t, tokens, 1, src)
+		assert.Equal(t, src, string(tokens[0].Text), src)
+		assert.Equal(t, expected, string(tokens[0].Literal), src)
+	}
+
+	for _, src := range []string{`"\x"`, `"\u12"`, `"\u12g4"`, `"abc\"`, `"\`} {
+		_, err := NewScanner(src).scanTokens()
+		require.Error(t, err, src)
+	}
+}
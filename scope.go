@@ -0,0 +1,246 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+)
+
+var (
+	ErrReadOnlyScope = errors.New("scope is read-only")
+	ErrNotAStruct    = errors.New("not a struct")
+)
+
+// Scope resolves the variables referenced by SymbolNodes during evaluation.
+type Scope interface {
+	Get(name string) (Value, bool)
+	Set(name string, v Value) error
+	Has(name string) bool
+	// Keys lists every name the scope can resolve
+	Keys() []string
+}
+
+// MapScope is the simplest Scope, a plain map of names to values.
+type MapScope map[string]Value
+
+func (m MapScope) Get(name string) (Value, bool) {
+	v, ok := m[name]
+	return v, ok
+}
+
+func (m MapScope) Set(name string, v Value) error {
+	m[name] = v
+	return nil
+}
+
+func (m MapScope) Has(name string) bool {
+	_, ok := m[name]
+	return ok
+}
+
+func (m MapScope) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var _ Scope = (MapScope)(nil)
+
+// StructScope is a read-only Scope over the fields of a Go struct. fields are
+// named by their `math:"name"` tag, or by the field name when untagged.
+// `math:"-"` hides a field. integer and float fields read as float64
+type StructScope struct {
+	value  reflect.Value
+	fields map[string][]int
+	keys   []string
+}
+
+// NewStructScope wraps a struct or a pointer to a struct. with a pointer, later
+// changes to the struct are visible through the scope
+func NewStructScope(v any) (*StructScope, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrNotAStruct, v)
+	}
+
+	s := &StructScope{
+		value:  rv,
+		fields: map[string][]int{},
+	}
+
+	for _, f := range reflect.VisibleFields(rv.Type()) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("math"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		if _, dup := s.fields[name]; dup {
+			continue
+		}
+
+		s.fields[name] = f.Index
+		s.keys = append(s.keys, name)
+	}
+
+	return s, nil
+}
+
+func (s *StructScope) Get(name string) (Value, bool) {
+	idx, ok := s.fields[name]
+	if !ok {
+		return nil, false
+	}
+
+	field, err := s.value.FieldByIndexErr(idx)
+	if err != nil {
+		// field is promoted through a nil embedded pointer
+		return nil, true
+	}
+
+	return fromReflect(field), true
+}
+
+func (s *StructScope) Set(name string, _ Value) error {
+	return fmt.Errorf("%w: cannot set %s", ErrReadOnlyScope, name)
+}
+
+func (s *StructScope) Has(name string) bool {
+	_, ok := s.fields[name]
+	return ok
+}
+
+// Keys returns the field names in declaration order
+func (s *StructScope) Keys() []string {
+	return append([]string(nil), s.keys...)
+}
+
+var _ Scope = (*StructScope)(nil)
+
+// ChildScope holds its own variables and falls back to a parent for reads.
+// writes always land in the child, so the parent is never modified
+type ChildScope struct {
+	parent Scope
+	local  MapScope
+}
+
+func NewChildScope(parent Scope) *ChildScope {
+	return &ChildScope{parent: parent, local: MapScope{}}
+}
+
+func (c *ChildScope) Parent() Scope { return c.parent }
+
+func (c *ChildScope) Get(name string) (Value, bool) {
+	if v, ok := c.local[name]; ok {
+		return v, true
+	}
+	if c.parent == nil {
+		return nil, false
+	}
+	return c.parent.Get(name)
+}
+
+func (c *ChildScope) Set(name string, v Value) error {
+	c.local[name] = v
+	return nil
+}
+
+func (c *ChildScope) Has(name string) bool {
+	return c.local.Has(name) || (c.parent != nil && c.parent.Has(name))
+}
+
+// Keys returns the sorted union of the child's and the parent's names
+func (c *ChildScope) Keys() []string {
+	seen := map[string]struct{}{}
+	keys := c.local.Keys()
+	for _, k := range keys {
+		seen[k] = struct{}{}
+	}
+
+	if c.parent != nil {
+		for _, k := range c.parent.Keys() {
+			if _, ok := seen[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+var _ Scope = (*ChildScope)(nil)
+
+// maxExactInt bounds the integers that a float64 holds exactly
+const maxExactInt = 1 << 53
+
+// fromReflect converts a Go value into the Value representation used by the
+// evaluator, so every numeric kind becomes a float64. integers past 2^53
+// become bigints instead, which keep all their digits
+func fromReflect(rv reflect.Value) Value {
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := rv.Int()
+		if i < -maxExactInt || i > maxExactInt {
+			return big.NewInt(i)
+		}
+		return float64(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > maxExactInt {
+			return new(big.Int).SetUint64(u)
+		}
+		return float64(u)
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return rv.Complex()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		// slices read as arrays, converting their elements like fields
+		out := make([]Value, rv.Len())
+		for i := range out {
+			out[i] = fromReflect(rv.Index(i))
+		}
+		return out
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Struct && rv.CanInterface() {
+			// values such as *big.Float are used through their pointer
+			return rv.Interface()
+		}
+		return fromReflect(rv.Elem())
+	default:
+		if !rv.CanInterface() {
+			return nil
+		}
+		return rv.Interface()
+	}
+}
@@ -0,0 +1,128 @@
+package mathematigo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapScope(t *testing.T) {
+	s := MapScope{"b": 2.0}
+
+	require.NoError(t, s.Set("a", 1.0))
+
+	v, ok := s.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, 1.0, v)
+
+	assert.True(t, s.Has("b"))
+	assert.False(t, s.Has("c"))
+	assert.Equal(t, []string{"a", "b"}, s.Keys())
+}
+
+type embeddedFields struct {
+	Region string `math:"region"`
+}
+
+type orderFields struct {
+	embeddedFields
+	Qty      int     `math:"qty"`
+	Price    float32 `math:"price"`
+	Express  bool
+	Customer *string `math:"customer"`
+	Secret   string  `math:"-"`
+	internal int
+}
+
+func TestStructScope(t *testing.T) {
+	order := &orderFields{
+		embeddedFields: embeddedFields{Region: "eu"},
+		Qty:            3,
+		Price:          2.5,
+		Express:        true,
+		Secret:         "hidden",
+		internal:       1,
+	}
+
+	s, err := NewStructScope(order)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"region", "qty", "price", "Express", "customer"}, s.Keys())
+	assert.False(t, s.Has("Secret"))
+	assert.False(t, s.Has("internal"))
+
+	v, ok := s.Get("qty")
+	require.True(t, ok)
+	assert.Equal(t, 3.0, v)
+
+	v, ok = s.Get("customer")
+	require.True(t, ok)
+	assert.Nil(t, v)
+
+	// reads go through the pointer
+	order.Qty = 4
+	v, _ = s.Get("qty")
+	assert.Equal(t, 4.0, v)
+
+	require.ErrorIs(t, s.Set("qty", 1.0), ErrReadOnlyScope)
+
+	assert.Equal(t, 10.0, evalString(t, "qty * price", s))
+	assert.Equal(t, true, evalString(t, `region == "eu"`, s))
+}
+
+func TestStructScopeRejectsNonStructs(t *testing.T) {
+	_, err := NewStructScope(map[string]any{})
+	require.ErrorIs(t, err, ErrNotAStruct)
+
+	_, err = NewStructScope((*orderFields)(nil))
+	require.ErrorIs(t, err, ErrNotAStruct)
+}
+
+func TestChildScope(t *testing.T) {
+	parent := MapScope{"a": 1.0, "b": 2.0}
+	child := NewChildScope(parent)
+
+	require.NoError(t, child.Set("b", 20.0))
+	require.NoError(t, child.Set("c", 30.0))
+
+	v, ok := child.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, 1.0, v)
+
+	v, _ = child.Get("b")
+	assert.Equal(t, 20.0, v)
+
+	assert.True(t, child.Has("c"))
+	assert.Equal(t, []string{"a", "b", "c"}, child.Keys())
+
+	// the parent is untouched
+	assert.Equal(t, MapScope{"a": 1.0, "b": 2.0}, parent)
+}
+
+func TestChildScopeOverReadOnlyParent(t *testing.T) {
+	parent, err := NewStructScope(orderFields{Qty: 2})
+	require.NoError(t, err)
+
+	child := NewChildScope(parent)
+	require.NoError(t, child.Set("qty", 5.0))
+
+	v, _ := child.Get("qty")
+	assert.Equal(t, 5.0, v)
+
+	v, _ = parent.Get("qty")
+	assert.Equal(t, 2.0, v)
+}
+
+func TestStructScopeSlices(t *testing.T) {
+	s, err := NewStructScope(struct {
+		Limits []int
+		Tags   [2]string
+		Empty  []float64
+	}{Limits: []int{1, 10}, Tags: [2]string{"a", "b"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Value{1.0, 10.0}, evalString(t, "Limits", s))
+	assert.Equal(t, []Value{"a", "b"}, evalString(t, "Tags", s))
+	assert.Nil(t, evalString(t, "Empty", s))
+}
@@ -0,0 +1,54 @@
+package mathematigo
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// substr returns length characters of s from the 1-based position start,
+// fewer when s ends first. like indices, positions count characters, not
+// bytes
+func substr(s string, start, length int) (string, error) {
+	runes := []rune(s)
+	if start < 1 || start > len(runes)+1 {
+		return "", fmt.Errorf("%w: start in function substr must be in the range of 1-%d", ErrArgumentValue, len(runes)+1)
+	}
+	if length < 0 {
+		return "", fmt.Errorf("%w: length in function substr must not be negative", ErrArgumentValue)
+	}
+
+	from := start - 1
+	return string(runes[from : from+min(length, len(runes)-from)]), nil
+}
+
+// registerStrings adds the string functions. mathjs only has concat and
+// format, the others follow the string methods of JavaScript, with positions
+// that start at 1 like indices do. concat joins strings here, its array
+// overload is in registerArrays. numbers are not converted, format them
+// first
+func registerStrings(r *Registry) {
+	r.MustRegister("concat", func(s string, rest ...string) string { return s + strings.Join(rest, "") })
+	r.MustRegister("substr", func(s string, start int) (string, error) {
+		return substr(s, start, utf8.RuneCountInString(s))
+	})
+	r.MustRegister("substr", substr)
+	r.MustRegister("upper", strings.ToUpper)
+	r.MustRegister("lower", strings.ToLower)
+	r.MustRegister("length", utf8.RuneCountInString)
+	r.MustRegister("length", func(a []Value) int { return len(a) })
+	r.MustRegister("contains", strings.Contains)
+	r.MustRegister("startsWith", strings.HasPrefix)
+	// every occurrence is replaced
+	r.MustRegister("replace", strings.ReplaceAll)
+	r.MustRegister("split", func(s, sep string) []Value {
+		parts := strings.Split(s, sep)
+		out := make([]Value, len(parts))
+		for i, p := range parts {
+			out[i] = p
+		}
+		return out
+	})
+	// strings are quoted, as in mathjs
+	r.MustRegister("format", func(v Value) string { return Format(v) })
+}
@@ -0,0 +1,59 @@
+package mathematigo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringFunctions(t *testing.T) {
+	cases := map[string]Value{
+		`concat("2023-12-23 ", "15:41")`:       "2023-12-23 15:41",
+		`concat("a", "b", "c")`:                "abc",
+		`concat("a")`:                          "a",
+		`substr("hello", 2, 3)`:                "ell",
+		`substr("hello", 2)`:                   "ello",
+		`substr("hello", 4, 10)`:               "lo",
+		`substr("hello", 6)`:                   "",
+		`substr("héllo", 2, 1)`:                "é",
+		`upper("abc")`:                         "ABC",
+		`lower("ÀBC")`:                         "àbc",
+		`length("héllo")`:                      5.0,
+		`length([1, 2, 3])`:                    3.0,
+		`contains("pattern", "tt")`:            true,
+		`contains("pattern", "x")`:             false,
+		`startsWith("2023-12-23", "2023")`:     true,
+		`replace("a-b-c", "-", "+")`:           "a+b+c",
+		`split("a,b,c", ",")`:                  []Value{"a", "b", "c"},
+		`format(1/3)`:                          "0.3333333333333333",
+		`format("a\"b")`:                       `"a\"b"`,
+		`format([1, true])`:                    "[1, true]",
+		`concat("ID-", format(42))`:            "ID-42",
+		`length(concat("a\n", 'b'))`:           3.0,
+		`upper(x)`:                             "HI",
+		`contains(lower("Hello World"), "wo")`: true,
+	}
+
+	for expr, expected := range cases {
+		assert.Equal(t, expected, evalString(t, expr, MapScope{"x": "hi"}), expr)
+	}
+}
+
+func TestStringFunctionErrors(t *testing.T) {
+	cases := map[string]error{
+		`substr("hello", 0)`:     ErrArgumentValue,
+		`substr("hello", 7)`:     ErrArgumentValue,
+		`substr("hello", 1, -1)`: ErrArgumentValue,
+		`concat("a", 1)`:         ErrArgumentType,
+		`upper(1)`:               ErrArgumentType,
+		`split("a")`:             ErrArity,
+	}
+
+	for expr, expected := range cases {
+		node, err := Parse(expr)
+		require.NoError(t, err, expr)
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, expected, expr)
+	}
+}
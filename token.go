@@ -28,6 +28,19 @@ const (
 	Ampersand
 	Mod
 	Caret
+	OpenBracket
+	CloseBracket
+	OpenBrace
+	CloseBrace
+	Colon
+	Question
+	LtLt
+	GtGt
+	GtGtGt
+	CaretPipe
+	Tilde
+	// Comment is trivia, the scanner only emits it when comments are kept
+	Comment
 )
 
 var ReservedIdentifiers map[string]struct{} = map[string]struct{}{
@@ -57,6 +70,16 @@ var RuneFalse SmartRune = []rune("false")
 var RuneTrue SmartRune = []rune("true")
 var RuneNull SmartRune = []rune("null")
 
+// RuneTo and RuneIn are the unit conversion operators, e.g. 5 cm to inch
+var RuneTo SmartRune = []rune("to")
+var RuneIn SmartRune = []rune("in")
+
+// the logical operators, see ReservedIdentifiers
+var RuneAnd SmartRune = []rune("and")
+var RuneOr SmartRune = []rune("or")
+var RuneXor SmartRune = []rune("xor")
+var RuneNot SmartRune = []rune("not")
+
 type Token struct {
 	Type TokenType
 
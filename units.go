@@ -0,0 +1,497 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrIncompatibleUnits = errors.New("incompatible units")
+	ErrUnknownUnit       = errors.New("unknown unit")
+)
+
+// base quantities of the SI, plus plane angle so that deg and rad convert
+const (
+	dimLength = iota
+	dimMass
+	dimTime
+	dimCurrent
+	dimTemperature
+	dimAmount
+	dimLuminosity
+	dimAngle
+	dimCount
+)
+
+// dimension holds the power of each base quantity, e.g. m/s^2 is length 1
+// and time -2
+type dimension [dimCount]int
+
+func (d dimension) add(other dimension, sign int) dimension {
+	for i := range d {
+		d[i] += sign * other[i]
+	}
+	return d
+}
+
+func (d dimension) scale(n int) dimension {
+	for i := range d {
+		d[i] *= n
+	}
+	return d
+}
+
+func (d dimension) isZero() bool {
+	return d == dimension{}
+}
+
+func dim(powers ...int) dimension {
+	var d dimension
+	copy(d[:], powers)
+	return d
+}
+
+type unitDef struct {
+	// scale is the size of the unit in SI base units. offset is added to
+	// values before scaling, only temperatures have one
+	scale  float64
+	offset float64
+	dim    dimension
+	// prefixed units accept SI prefixes, e.g. km or ms
+	prefixed bool
+}
+
+var unitPrefixes = []struct {
+	name  string
+	scale float64
+}{
+	// da comes before d so dam is decameter, not decimeter-something
+	{"da", 1e1}, {"h", 1e2}, {"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15},
+	{"d", 1e-1}, {"c", 1e-2}, {"m", 1e-3}, {"u", 1e-6}, {"n", 1e-9}, {"p", 1e-12}, {"f", 1e-15},
+}
+
+var (
+	dimLengthOnly = dim(1)
+	dimForce      = dim(1, 1, -2)
+	dimEnergy     = dim(2, 1, -2)
+	dimPower      = dim(2, 1, -3)
+	dimPressure   = dim(-1, 1, -2)
+	dimVolume     = dim(3)
+	dimCharge     = dim(0, 0, 1, 1)
+	dimVoltage    = dim(2, 1, -3, -1)
+	dimResistance = dim(2, 1, -3, -2)
+)
+
+var unitDefs = map[string]unitDef{
+	// length
+	"m":     {scale: 1, dim: dimLengthOnly, prefixed: true},
+	"meter": {scale: 1, dim: dimLengthOnly},
+	"inch":  {scale: 0.0254, dim: dimLengthOnly},
+	"ft":    {scale: 0.3048, dim: dimLengthOnly},
+	"yd":    {scale: 0.9144, dim: dimLengthOnly},
+	"mi":    {scale: 1609.344, dim: dimLengthOnly},
+	"mile":  {scale: 1609.344, dim: dimLengthOnly},
+
+	// mass, the SI base unit is kg
+	"g":     {scale: 1e-3, dim: dim(0, 1), prefixed: true},
+	"gram":  {scale: 1e-3, dim: dim(0, 1)},
+	"tonne": {scale: 1e3, dim: dim(0, 1)},
+	"lb":    {scale: 0.45359237, dim: dim(0, 1)},
+	"oz":    {scale: 0.028349523125, dim: dim(0, 1)},
+
+	// time
+	"s":      {scale: 1, dim: dim(0, 0, 1), prefixed: true},
+	"second": {scale: 1, dim: dim(0, 0, 1)},
+	"min":    {scale: 60, dim: dim(0, 0, 1)},
+	"minute": {scale: 60, dim: dim(0, 0, 1)},
+	"h":      {scale: 3600, dim: dim(0, 0, 1)},
+	"hour":   {scale: 3600, dim: dim(0, 0, 1)},
+	"day":    {scale: 86400, dim: dim(0, 0, 1)},
+	"week":   {scale: 604800, dim: dim(0, 0, 1)},
+
+	"A":   {scale: 1, dim: dim(0, 0, 0, 1), prefixed: true},
+	"mol": {scale: 1, dim: dim(0, 0, 0, 0, 0, 1), prefixed: true},
+	"cd":  {scale: 1, dim: dim(0, 0, 0, 0, 0, 0, 1), prefixed: true},
+
+	// temperature
+	"K":    {scale: 1, dim: dim(0, 0, 0, 0, 1), prefixed: true},
+	"degC": {scale: 1, offset: 273.15, dim: dim(0, 0, 0, 0, 1)},
+	"degF": {scale: 5.0 / 9, offset: 459.67, dim: dim(0, 0, 0, 0, 1)},
+
+	// angle
+	"rad": {scale: 1, dim: dim(0, 0, 0, 0, 0, 0, 0, 1), prefixed: true},
+	"deg": {scale: math.Pi / 180, dim: dim(0, 0, 0, 0, 0, 0, 0, 1)},
+
+	// derived
+	"N":   {scale: 1, dim: dimForce, prefixed: true},
+	"J":   {scale: 1, dim: dimEnergy, prefixed: true},
+	"cal": {scale: 4.184, dim: dimEnergy, prefixed: true},
+	"Wh":  {scale: 3600, dim: dimEnergy, prefixed: true},
+	"W":   {scale: 1, dim: dimPower, prefixed: true},
+	"Pa":  {scale: 1, dim: dimPressure, prefixed: true},
+	"bar": {scale: 1e5, dim: dimPressure, prefixed: true},
+	"atm": {scale: 101325, dim: dimPressure},
+	"Hz":  {scale: 1, dim: dim(0, 0, -1), prefixed: true},
+	"C":   {scale: 1, dim: dimCharge, prefixed: true},
+	"V":   {scale: 1, dim: dimVoltage, prefixed: true},
+	"ohm": {scale: 1, dim: dimResistance, prefixed: true},
+	"L":   {scale: 1e-3, dim: dimVolume, prefixed: true},
+	"l":   {scale: 1e-3, dim: dimVolume, prefixed: true},
+}
+
+// unitTerm is one named unit raised to a power, e.g. the s^2 of m/s^2
+type unitTerm struct {
+	name   string
+	scale  float64
+	offset float64
+	power  int
+}
+
+// Unit is a quantity with a physical unit, such as 5 cm. the value is kept
+// in SI base units; the terms only decide how it prints. a Unit without a
+// value, such as the cm in 5 cm, stands for one of itself and is what the
+// to and in operators expect on their right
+type Unit struct {
+	value    float64
+	hasValue bool
+	dim      dimension
+	terms    []unitTerm
+}
+
+// NewUnit returns value in the named unit, e.g. NewUnit(5, "cm")
+func NewUnit(value float64, name string) (*Unit, error) {
+	u, ok := lookupUnit(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownUnit, name)
+	}
+	return u.withDisplay(value), nil
+}
+
+// lookupUnit resolves a unit name, optionally with an SI prefix, to a Unit
+// without a value
+func lookupUnit(name string) (*Unit, bool) {
+	if def, ok := unitDefs[name]; ok {
+		return unitFromDef(name, def, 1), true
+	}
+
+	for _, p := range unitPrefixes {
+		rest, ok := strings.CutPrefix(name, p.name)
+		if !ok {
+			continue
+		}
+		if def, ok := unitDefs[rest]; ok && def.prefixed {
+			return unitFromDef(name, def, p.scale), true
+		}
+	}
+	return nil, false
+}
+
+func unitFromDef(name string, def unitDef, prefix float64) *Unit {
+	return &Unit{
+		dim:   def.dim,
+		terms: []unitTerm{{name: name, scale: def.scale * prefix, offset: def.offset, power: 1}},
+	}
+}
+
+// Value is the quantity in the unit's own terms, e.g. 5 for 5 cm
+func (u *Unit) Value() float64 {
+	return (u.si() / u.scale()) - u.offset()
+}
+
+// Units prints the unit without its value, e.g. m / s^2
+func (u *Unit) Units() string {
+	var num, den []string
+	for _, t := range u.terms {
+		switch {
+		case t.power == 1:
+			num = append(num, t.name)
+		case t.power > 1:
+			num = append(num, fmt.Sprintf("%s^%d", t.name, t.power))
+		case t.power == -1:
+			den = append(den, t.name)
+		default:
+			den = append(den, fmt.Sprintf("%s^%d", t.name, -t.power))
+		}
+	}
+
+	switch {
+	case len(den) == 0:
+		return strings.Join(num, " ")
+	case len(num) == 0:
+		parts := make([]string, 0, len(u.terms))
+		for _, t := range u.terms {
+			parts = append(parts, fmt.Sprintf("%s^%d", t.name, t.power))
+		}
+		return strings.Join(parts, " ")
+	case len(den) == 1:
+		return strings.Join(num, " ") + " / " + den[0]
+	default:
+		return strings.Join(num, " ") + " / (" + strings.Join(den, " ") + ")"
+	}
+}
+
+func (u *Unit) String() string {
+	if !u.hasValue {
+		return u.Units()
+	}
+	return formatFloat(u.Value()) + " " + u.Units()
+}
+
+// scale is the size of one of the unit in SI base units
+func (u *Unit) scale() float64 {
+	out := 1.0
+	for _, t := range u.terms {
+		out *= math.Pow(t.scale, float64(t.power))
+	}
+	return out
+}
+
+// offset only applies to a lone unit like degC, in a product such as
+// J / degC temperatures are differences and have no offset
+func (u *Unit) offset() float64 {
+	if len(u.terms) == 1 && u.terms[0].power == 1 {
+		return u.terms[0].offset
+	}
+	return 0
+}
+
+// si is the value in SI base units. a Unit without a value is one of itself
+func (u *Unit) si() float64 {
+	if u.hasValue {
+		return u.value
+	}
+	return u.scale()
+}
+
+// withDisplay returns a copy of u holding x in its own terms
+func (u *Unit) withDisplay(x float64) *Unit {
+	return &Unit{value: (x + u.offset()) * u.scale(), hasValue: true, dim: u.dim, terms: u.terms}
+}
+
+func (u *Unit) sameTerms(other *Unit) bool {
+	return slices.Equal(u.terms, other.terms)
+}
+
+func incompatibleUnitsErr(fn OperatorFnName, a, b Value) error {
+	return fmt.Errorf("%w: cannot apply %s to %s and %s", ErrIncompatibleUnits, fn, unitText(a), unitText(b))
+}
+
+func unitText(v Value) string {
+	if u, ok := v.(*Unit); ok {
+		return u.Units()
+	}
+	return typeOf(v)
+}
+
+// unitScalar converts the other operand of a unit operation to a float64
+func unitScalar(v Value) (float64, bool) {
+	if _, ok := v.(complex128); ok {
+		return 0, false
+	}
+	return toNumber(v)
+}
+
+// unitOperands accepts a Unit with a Unit or a number. x is nil when the
+// operand is not a Unit
+func unitOperands(fn OperatorFnName, a, b Value) (x, y *Unit, err error) {
+	x, _ = a.(*Unit)
+	y, _ = b.(*Unit)
+	if x == nil {
+		if _, ok := unitScalar(a); !ok {
+			return nil, nil, invalidOperandErr(fn, a, b)
+		}
+	}
+	if y == nil {
+		if _, ok := unitScalar(b); !ok {
+			return nil, nil, invalidOperandErr(fn, a, b)
+		}
+	}
+	return x, y, nil
+}
+
+func unitAdd(fn OperatorFnName, sign float64) func(a, b Value) (Value, error) {
+	return func(a, b Value) (Value, error) {
+		x, y, err := unitOperands(fn, a, b)
+		if err != nil {
+			return nil, err
+		}
+		if x == nil || y == nil || x.dim != y.dim {
+			return nil, incompatibleUnitsErr(fn, a, b)
+		}
+
+		// in the same terms, so 10 degC + 5 degC is 15 degC
+		if x.sameTerms(y) {
+			return x.withDisplay(x.Value() + sign*y.Value()), nil
+		}
+		if x.offset() != 0 || y.offset() != 0 {
+			return nil, fmt.Errorf("%w: cannot apply %s to %s and %s, convert them to the same unit first", ErrIncompatibleUnits, fn, x.Units(), y.Units())
+		}
+		return &Unit{value: x.si() + sign*y.si(), hasValue: true, dim: x.dim, terms: x.terms}, nil
+	}
+}
+
+// unitProduct multiplies, or divides when sign is -1, units and numbers.
+// results without a dimension, like km / m, become numbers
+func unitProduct(fn OperatorFnName, sign int) func(a, b Value) (Value, error) {
+	return func(a, b Value) (Value, error) {
+		x, y, err := unitOperands(fn, a, b)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case y == nil:
+			n, _ := unitScalar(b)
+			if sign < 0 {
+				n = 1 / n
+			}
+			return x.withDisplay(x.displayOrOne() * n), nil
+		case x == nil && sign > 0:
+			n, _ := unitScalar(a)
+			return y.withDisplay(y.displayOrOne() * n), nil
+		case x == nil:
+			n, _ := unitScalar(a)
+			inv := y.pow(-1)
+			return &Unit{value: n * inv.si(), hasValue: true, dim: inv.dim, terms: inv.terms}, nil
+		}
+
+		out := &Unit{
+			hasValue: x.hasValue || y.hasValue,
+			dim:      x.dim.add(y.dim, sign),
+			terms:    mergeTerms(x.terms, y.terms, sign),
+		}
+		if sign > 0 {
+			out.value = x.si() * y.si()
+		} else {
+			out.value = x.si() / y.si()
+		}
+
+		if out.dim.isZero() {
+			return out.value, nil
+		}
+		if !out.hasValue {
+			// the terms carry the scale of a Unit without a value
+			out.value = 0
+		}
+		return out, nil
+	}
+}
+
+func (u *Unit) displayOrOne() float64 {
+	if u.hasValue {
+		return u.Value()
+	}
+	return 1
+}
+
+func mergeTerms(a, b []unitTerm, sign int) []unitTerm {
+	out := slices.Clone(a)
+	for _, t := range b {
+		t.power *= sign
+		i := slices.IndexFunc(out, func(o unitTerm) bool { return o.name == t.name })
+		if i < 0 {
+			out = append(out, t)
+			continue
+		}
+		out[i].power += t.power
+		if out[i].power == 0 {
+			out = slices.Delete(out, i, i+1)
+		}
+	}
+	return out
+}
+
+func (u *Unit) pow(n int) *Unit {
+	terms := slices.Clone(u.terms)
+	for i := range terms {
+		terms[i].power *= n
+	}
+	return &Unit{
+		value:    math.Pow(u.si(), float64(n)),
+		hasValue: u.hasValue,
+		dim:      u.dim.scale(n),
+		terms:    terms,
+	}
+}
+
+func unitPow(a, b Value) (Value, error) {
+	x, ok := a.(*Unit)
+	if !ok {
+		return nil, invalidOperandErr(OperatorFnPower, a, b)
+	}
+	n, ok := unitScalar(b)
+	if !ok {
+		return nil, invalidOperandErr(OperatorFnPower, a, b)
+	}
+	i, ok := floatToInteger(n)
+	if !ok {
+		return nil, fmt.Errorf("%w: units can only be raised to integer powers", ErrInvalidOperand)
+	}
+
+	out := x.pow(int(i))
+	if out.dim.isZero() {
+		return 1.0, nil
+	}
+	if !out.hasValue {
+		out.value = 0
+	}
+	return out, nil
+}
+
+func unitNegate(u *Unit) (Value, error) {
+	return u.withDisplay(-u.displayOrOne()), nil
+}
+
+// unitConvert implements the to and in operators
+func unitConvert(a, b Value) (Value, error) {
+	x, okX := a.(*Unit)
+	y, okY := b.(*Unit)
+	if !okX || !okY {
+		return nil, invalidOperandErr(OperatorFnTo, a, b)
+	}
+	if y.hasValue {
+		return nil, fmt.Errorf("%w: cannot convert to %s, the target unit must not have a value", ErrInvalidOperand, y)
+	}
+	if x.dim != y.dim {
+		return nil, fmt.Errorf("%w: cannot convert %s to %s", ErrIncompatibleUnits, x.Units(), y.Units())
+	}
+
+	return &Unit{value: x.si(), hasValue: true, dim: y.dim, terms: y.terms}, nil
+}
+
+// compareUnits orders two units of the same dimension
+func compareUnits(fn OperatorFnName, a, b Value) (int, bool, error) {
+	x, okX := a.(*Unit)
+	y, okY := b.(*Unit)
+	if !okX || !okY || x.dim != y.dim {
+		return 0, false, incompatibleUnitsErr(fn, a, b)
+	}
+	if x.sameTerms(y) && x.offset() != 0 {
+		cmp, ok := compareFloats(x.Value(), y.Value())
+		return cmp, ok, nil
+	}
+	cmp, ok := compareFloats(x.si(), y.si())
+	return cmp, ok, nil
+}
+
+func isUnit(v Value) bool {
+	_, ok := v.(*Unit)
+	return ok
+}
+
+// registerUnits adds the Unit overloads of the standard functions
+func registerUnits(r *Registry) {
+	r.MustRegister("abs", func(u *Unit) *Unit { return u.withDisplay(math.Abs(u.displayOrOne())) })
+
+	for name, fn := range map[string]func(float64) float64{"sin": math.Sin, "cos": math.Cos, "tan": math.Tan} {
+		r.MustRegister(name, func(u *Unit) (float64, error) {
+			if u.dim != dim(0, 0, 0, 0, 0, 0, 0, 1) {
+				return 0, fmt.Errorf("%w: %s expects an angle, got %s", ErrArgumentType, name, u.Units())
+			}
+			return fn(u.si()), nil
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package mathematigo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evalUnit(t *testing.T, expr string) *Unit {
+	t.Helper()
+
+	v := evalString(t, expr, nil)
+	require.IsType(t, &Unit{}, v, expr)
+	return v.(*Unit)
+}
+
+func TestUnitArithmetic(t *testing.T) {
+	cases := []struct {
+		expr  string
+		value float64
+		units string
+	}{
+		{"5 cm + 2 inch", 10.08, "cm"},
+		{"2 inch + 5 cm", 3.968503937007874, "inch"},
+		{"9.81 m/s^2 * 3 kg to N", 29.43, "N"},
+		{"120 km/h in m/s", 33.333333333333336, "m / s"},
+		{"1 mi to km", 1.609344, "km"},
+		{"5 m * 2 m", 10, "m^2"},
+		{"(3 m) ^ 2", 9, "m^2"},
+		{"-5 cm", -5, "cm"},
+		{"3 * kg", 3, "kg"},
+		{"10 kg / 4", 2.5, "kg"},
+		{"1 / (2 s)", 0.5, "s^-1"},
+		{"1 J / (kg K)", 1, "J / (kg K)"},
+		{"abs(-2 cm)", 2, "cm"},
+		{"10 degC + 5 degC", 15, "degC"},
+		{"100 degC to degF", 212, "degF"},
+		{"300 K to degC", 26.85, "degC"},
+	}
+
+	for _, c := range cases {
+		u := evalUnit(t, c.expr)
+		assert.InDelta(t, c.value, u.Value(), 1e-9, c.expr)
+		assert.Equal(t, c.units, u.Units(), c.expr)
+	}
+}
+
+func TestUnitDimensionless(t *testing.T) {
+	// results without a dimension are plain numbers
+	assert.InDelta(t, 1000.0, evalString(t, "km / m", nil), 1e-9)
+	assert.InDelta(t, 2.0, evalString(t, "(4 m) / (2 m)", nil), 1e-9)
+	assert.InDelta(t, 1.0, evalString(t, "sin(90 deg)", nil), 1e-12)
+}
+
+func TestUnitComparisons(t *testing.T) {
+	assert.Equal(t, true, evalString(t, "5 cm == 50 mm", nil))
+	assert.Equal(t, true, evalString(t, "1 ft < 1 m", nil))
+	assert.Equal(t, false, evalString(t, "5 cm > 2 inch", nil))
+	assert.Equal(t, false, evalString(t, "5 cm == 5", nil))
+}
+
+func TestUnitErrors(t *testing.T) {
+	cases := map[string]error{
+		"5 cm + 2 kg":   ErrIncompatibleUnits,
+		"5 cm - 2":      ErrIncompatibleUnits,
+		"2 cm to kg":    ErrIncompatibleUnits,
+		"5 cm < 2 s":    ErrIncompatibleUnits,
+		"5 cm to 2 m":   ErrInvalidOperand,
+		"(2 m) ^ 0.5":   ErrInvalidOperand,
+		"5 cm % 2":      ErrInvalidOperand,
+		"sin(2 m)":      ErrArgumentType,
+		"5 to cm":       ErrInvalidOperand,
+		"2 foo":         ErrUndefinedSymbol,
+		"10 degC + 5 K": ErrIncompatibleUnits,
+	}
+
+	for expr, expected := range cases {
+		node, err := Parse(expr)
+		require.NoError(t, err, expr)
+		_, err = Evaluate(node, nil)
+		require.ErrorIs(t, err, expected, expr)
+	}
+}
+
+func TestUnitConversionParses(t *testing.T) {
+	node, err := Parse("120 km/h in m/s")
+	require.NoError(t, err)
+
+	op, ok := node.(*OperatorNode)
+	require.True(t, ok)
+	assert.Equal(t, OperatorFnTo, op.Fn)
+	assert.Equal(t, "in", op.Op)
+
+	// conversion binds looser than arithmetic and tighter than comparisons
+	node, err = Parse("1 m to cm > 2 cm")
+	require.NoError(t, err)
+	op, ok = node.(*OperatorNode)
+	require.True(t, ok)
+	assert.Equal(t, OperatorFnGt, op.Fn)
+}
+
+func TestUnitsEverywhere(t *testing.T) {
+	node, err := Parse("distance / 2 h to m/s")
+	require.NoError(t, err)
+
+	distance, err := NewUnit(90, "km")
+	require.NoError(t, err)
+	scope := MapScope{"distance": distance}
+
+	p, err := Compile(node)
+	require.NoError(t, err)
+	v, err := p.Eval(scope)
+	require.NoError(t, err)
+	assert.Equal(t, "12.5 m / s", Format(v))
+
+	bc, err := CompileBytecode(node)
+	require.NoError(t, err)
+	v, err = NewVM(bc).Run(scope)
+	require.NoError(t, err)
+	assert.Equal(t, "12.5 m / s", Format(v))
+
+	_, err = NewEvaluator(WithUnits(false)).Evaluate(node, scope)
+	require.ErrorIs(t, err, ErrUndefinedSymbol)
+
+	// the scope shadows units
+	assert.Equal(t, 6.0, evalString(t, "2 h", MapScope{"h": 3.0}))
+
+	_, err = NewUnit(50, "mph")
+	require.ErrorIs(t, err, ErrUnknownUnit)
+}
+
+func TestFormatUnit(t *testing.T) {
+	assert.Equal(t, "33.333333333333336 m / s", Format(evalString(t, "120 km/h to m/s", nil)))
+	assert.Equal(t, "m / s^2", Format(evalString(t, "m / s^2", nil)))
+}
@@ -0,0 +1,330 @@
+package mathematigo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrNotANumber = errors.New("result is not a number")
+
+type vmKind uint8
+
+const (
+	// vmFloat values live unboxed in vmValue.f
+	vmFloat vmKind = iota
+	// vmBoxed values live in vmValue.v
+	vmBoxed
+	// vmMissing marks a slot the scope did not define
+	vmMissing
+)
+
+// vmValue keeps floats out of interfaces so pure float programs run without
+// allocating
+type vmValue struct {
+	f    float64
+	v    Value
+	kind vmKind
+}
+
+func toVMValue(v Value) vmValue {
+	if f, ok := v.(float64); ok {
+		return vmValue{f: f, kind: vmFloat}
+	}
+	return vmValue{v: v, kind: vmBoxed}
+}
+
+func (v vmValue) value() Value {
+	if v.kind == vmFloat {
+		return v.f
+	}
+	return v.v
+}
+
+// VM runs Bytecode. it reuses its stack between runs, so a VM must not be
+// used from several goroutines at once; create one VM per goroutine instead
+type VM struct {
+	bc         *Bytecode
+	constants  []vmValue
+	registered []Function
+	// defaults are the evaluator's builtins for slots the scope leaves
+	// undefined, vmMissing otherwise
+	defaults []vmValue
+
+	stack     []vmValue
+	slots     []vmValue
+	functions []Function
+}
+
+// NewVM prepares bc to run with the default Evaluator's functions
+func NewVM(bc *Bytecode) *VM {
+	return NewEvaluator().NewVM(bc)
+}
+
+// NewVM prepares bc to run, binding its function calls to the evaluator's
+// registered functions
+func (e *Evaluator) NewVM(bc *Bytecode) *VM {
+	vm := &VM{
+		bc:         bc,
+		constants:  make([]vmValue, len(bc.constants)),
+		registered: make([]Function, len(bc.functions)),
+		defaults:   make([]vmValue, len(bc.slots)),
+		stack:      make([]vmValue, bc.maxStack),
+		slots:      make([]vmValue, len(bc.slots)),
+		functions:  make([]Function, len(bc.functions)),
+	}
+
+	for i, c := range bc.constants {
+		vm.constants[i] = toVMValue(c)
+	}
+
+	for i, name := range bc.functions {
+		vm.registered[i] = e.function(name)
+	}
+
+	for i, name := range bc.slots {
+		if v, ok := e.builtin(name); ok {
+			vm.defaults[i] = toVMValue(v)
+		} else {
+			vm.defaults[i] = vmValue{kind: vmMissing}
+		}
+	}
+
+	return vm
+}
+
+// Run evaluates the bytecode against scope, which may be nil
+func (vm *VM) Run(scope Scope) (Value, error) {
+	out, err := vm.run(scope)
+	if err != nil {
+		return nil, err
+	}
+	return out.value(), nil
+}
+
+// RunFloat is Run for programs that produce a number. it does not allocate
+// when every value involved is a float64
+func (vm *VM) RunFloat(scope Scope) (float64, error) {
+	out, err := vm.run(scope)
+	if err != nil {
+		return 0, err
+	}
+	if out.kind != vmFloat {
+		return 0, fmt.Errorf("%w: got %s", ErrNotANumber, typeOf(out.v))
+	}
+	return out.f, nil
+}
+
+// bind loads the variables and the scope's function overrides into slots
+func (vm *VM) bind(scope Scope) {
+	for i, name := range vm.bc.slots {
+		if scope == nil {
+			vm.slots[i] = vm.defaults[i]
+			continue
+		}
+		if v, ok := scope.Get(name); ok {
+			vm.slots[i] = toVMValue(v)
+		} else {
+			vm.slots[i] = vm.defaults[i]
+		}
+	}
+
+	for i, name := range vm.bc.functions {
+		vm.functions[i] = vm.registered[i]
+		if scope == nil {
+			continue
+		}
+		// functions in the scope shadow registered ones, same as Evaluate
+		if v, ok := scope.Get(name); ok {
+			if fn, ok := v.(Function); ok {
+				vm.functions[i] = fn
+			}
+		}
+	}
+}
+
+func (vm *VM) run(scope Scope) (vmValue, error) {
+	vm.bind(scope)
+
+	code := vm.bc.code
+	stack := vm.stack
+	sp := 0
+
+	for ip := 0; ip < len(code); {
+		op := opcode(code[ip])
+		ip++
+
+		switch {
+		case op == bcConst:
+			stack[sp] = vm.constants[readUint16(code[ip:])]
+			sp++
+			ip += 2
+		case op == bcLoad:
+			slot := readUint16(code[ip:])
+			v := vm.slots[slot]
+			if v.kind == vmMissing {
+				return vmValue{}, fmt.Errorf("%w: %s", ErrUndefinedSymbol, vm.bc.slots[slot])
+			}
+			stack[sp] = v
+			sp++
+			ip += 2
+		case op == bcStore:
+			slot := readUint16(code[ip:])
+			ip += 2
+			vm.slots[slot] = stack[sp-1]
+			if scope != nil {
+				if err := scope.Set(vm.bc.slots[slot], stack[sp-1].value()); err != nil {
+					return vmValue{}, err
+				}
+			}
+		case op == bcPop:
+			sp--
+		case op == bcJump:
+			ip = readUint16(code[ip:])
+		case op == bcJumpIfFalse || op == bcJumpIfTrue:
+			target := readUint16(code[ip:])
+			ip += 2
+			sp--
+			ok, err := truthyVM(stack[sp])
+			if err != nil {
+				return vmValue{}, err
+			}
+			if ok == (op == bcJumpIfTrue) {
+				ip = target
+			}
+		case op == bcCall:
+			idx := readUint16(code[ip:])
+			argc := int(code[ip+2])
+			ip += 3
+
+			fn := vm.functions[idx]
+			if fn == nil {
+				return vmValue{}, fmt.Errorf("%w: %s", ErrUndefinedFunction, vm.bc.functions[idx])
+			}
+
+			args := make([]Value, argc)
+			for i := range args {
+				args[i] = stack[sp-argc+i].value()
+			}
+			sp -= argc
+
+			out, err := fn(args...)
+			if err != nil {
+				return vmValue{}, err
+			}
+			stack[sp] = toVMValue(out)
+			sp++
+		case op.isUnary():
+			out, err := unaryVM(op, stack[sp-1])
+			if err != nil {
+				return vmValue{}, err
+			}
+			stack[sp-1] = out
+		default:
+			out, err := binaryVM(op, stack[sp-2], stack[sp-1])
+			if err != nil {
+				return vmValue{}, err
+			}
+			sp--
+			stack[sp-1] = out
+		}
+	}
+
+	return stack[sp-1], nil
+}
+
+func truthyVM(v vmValue) (bool, error) {
+	if v.kind == vmFloat {
+		return v.f != 0 && !math.IsNaN(v.f), nil
+	}
+	return truthy(v.v)
+}
+
+func floatVM(f float64) vmValue { return vmValue{f: f, kind: vmFloat} }
+
+// bools do not allocate when boxed
+func boolVM(b bool) vmValue { return vmValue{v: b, kind: vmBoxed} }
+
+func unaryVM(op opcode, a vmValue) (vmValue, error) {
+	if a.kind == vmFloat {
+		switch op {
+		case bcUnaryMinus:
+			return floatVM(-a.f), nil
+		case bcFactorial:
+			out, err := factorialFloat(a.f)
+			return floatVM(out), err
+		case bcNot:
+			return boolVM(a.f == 0 || math.IsNaN(a.f)), nil
+		case bcBitNot:
+			if i, ok := floatToInteger(a.f); ok {
+				return floatVM(float64(^i)), nil
+			}
+		}
+	}
+
+	out, err := unaryOperators[opcodeOperators[op]](a.value())
+	if err != nil {
+		return vmValue{}, err
+	}
+	return toVMValue(out), nil
+}
+
+// binaryVM handles two floats inline and defers everything else to the
+// operators shared with the evaluator
+func binaryVM(op opcode, a, b vmValue) (vmValue, error) {
+	if a.kind == vmFloat && b.kind == vmFloat {
+		x, y := a.f, b.f
+		switch op {
+		case bcAdd:
+			return floatVM(x + y), nil
+		case bcSubtract:
+			return floatVM(x - y), nil
+		case bcMultiply:
+			return floatVM(x * y), nil
+		case bcDivide:
+			return floatVM(x / y), nil
+		case bcMod:
+			return floatVM(floorMod(x, y)), nil
+		case bcPow:
+			// fractional powers of negative numbers are complex
+			if x >= 0 || y == math.Trunc(y) {
+				return floatVM(math.Pow(x, y)), nil
+			}
+		case bcBitOr, bcBitAnd, bcBitXor:
+			i, okX := floatToInteger(x)
+			j, okY := floatToInteger(y)
+			if okX && okY {
+				switch op {
+				case bcBitOr:
+					return floatVM(float64(i | j)), nil
+				case bcBitAnd:
+					return floatVM(float64(i & j)), nil
+				default:
+					return floatVM(float64(i ^ j)), nil
+				}
+			}
+		case bcEqual:
+			return boolVM(nearlyEqual(x, y)), nil
+		case bcUnequal:
+			return boolVM(!nearlyEqual(x, y)), nil
+		case bcLarger, bcLargerEq, bcSmaller, bcSmallerEq:
+			cmp, ok := compareFloats(x, y)
+			switch op {
+			case bcLarger:
+				return boolVM(ok && cmp > 0), nil
+			case bcLargerEq:
+				return boolVM(ok && cmp >= 0), nil
+			case bcSmaller:
+				return boolVM(ok && cmp < 0), nil
+			default:
+				return boolVM(ok && cmp <= 0), nil
+			}
+		}
+	}
+
+	out, err := binaryOperators[opcodeOperators[op]](a.value(), b.value())
+	if err != nil {
+		return vmValue{}, err
+	}
+	return toVMValue(out), nil
+}
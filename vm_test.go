@@ -0,0 +1,247 @@
+package mathematigo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compileBytecodeString(t testing.TB, expr string) *Bytecode {
+	t.Helper()
+
+	node, err := Parse(expr)
+	require.NoError(t, err)
+
+	bc, err := CompileBytecode(node)
+	require.NoError(t, err)
+
+	return bc
+}
+
+func TestVMMatchesEvaluate(t *testing.T) {
+	exprs := []string{
+		"1 + 2 * 3",
+		"(a + b) ^ 2 % 7",
+		"-a! + 6 | 1",
+		"7 & b",
+		"a > b == false",
+		"0.1 + 0.2 == 0.3",
+		`"gold" == tier`,
+		"true + 1",
+		"a\nb\na * b",
+		"double(a) - b",
+		"null",
+		"c = a * b\nc + 1",
+		"a > b ? a : b - 1",
+		"a < b ? a : b - 1",
+		"c = a * b; c + 1",
+		"c = a; d = b;",
+		"a\nb;\n;",
+		"a > b and b > 0 or not a",
+		"a and b and 0 or a xor b",
+		"not (a or b) or not 0",
+		"~a ^| b << 2 >> 1 >>> 1",
+		"a & (1 << b) != 0",
+		`tier == "gold" ? a ? 1 : 2 : b ? 3 : 4`,
+		"(a ? b : 0) + (0 ? 1 : null)",
+	}
+
+	double := Function(func(args ...Value) (Value, error) {
+		return args[0].(float64) * 2, nil
+	})
+	e := NewEvaluator(WithFunction("double", double))
+	scope := MapScope{"a": 3.0, "b": 4.0, "tier": "gold"}
+
+	for _, expr := range exprs {
+		node, err := Parse(expr)
+		require.NoError(t, err)
+
+		expected, err := e.Evaluate(node, scope)
+		require.NoError(t, err, expr)
+
+		bc, err := CompileBytecode(node)
+		require.NoError(t, err, expr)
+
+		actual, err := e.NewVM(bc).Run(scope)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, actual, expr)
+	}
+}
+
+func TestBytecodeSlots(t *testing.T) {
+	bc := compileBytecodeString(t, "x * y + x")
+	assert.Equal(t, []string{"x", "y"}, bc.Slots())
+}
+
+func TestVMErrors(t *testing.T) {
+	vm := NewVM(compileBytecodeString(t, "missing(x)"))
+
+	_, err := vm.Run(MapScope{"x": 1.0})
+	require.ErrorIs(t, err, ErrUndefinedFunction)
+
+	_, err = vm.Run(nil)
+	require.ErrorIs(t, err, ErrUndefinedSymbol)
+
+	_, err = NewVM(compileBytecodeString(t, `"a" + 1`)).Run(nil)
+	require.ErrorIs(t, err, ErrInvalidOperand)
+
+	_, err = NewVM(compileBytecodeString(t, `1 < 2`)).RunFloat(nil)
+	require.ErrorIs(t, err, ErrNotANumber)
+
+	_, err = CompileBytecode(NewOperatorNode("?", "unknown", NewFloatNode(1), NewFloatNode(2)))
+	require.ErrorIs(t, err, ErrUnsupportedOperator)
+}
+
+func TestVMRunFloatDoesNotAllocate(t *testing.T) {
+	vm := NewVM(compileBytecodeString(t, "(price * qty - discount) * (1 + tax) % 7 ^ 2 + 3!"))
+	scope := MapScope{"price": 19.99, "qty": 3.0, "discount": 5.0, "tax": 0.2}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := vm.RunFloat(scope); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestBytecodeRoundTrip(t *testing.T) {
+	bc := compileBytecodeString(t, `f(x, "s", true, null, false) + 1.5`)
+
+	data, err := bc.MarshalBinary()
+	require.NoError(t, err)
+
+	loaded := &Bytecode{}
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	assert.Equal(t, bc, loaded)
+
+	f := Function(func(args ...Value) (Value, error) {
+		return float64(len(args)), nil
+	})
+	v, err := NewVM(loaded).Run(MapScope{"x": 1.0, "f": f})
+	require.NoError(t, err)
+	assert.Equal(t, 6.5, v)
+}
+
+func TestBytecodeRejectsCorruptData(t *testing.T) {
+	bc := compileBytecodeString(t, "x + 1")
+
+	data, err := bc.MarshalBinary()
+	require.NoError(t, err)
+
+	for i := range data {
+		require.ErrorIs(t, (&Bytecode{}).UnmarshalBinary(data[:i]), ErrInvalidBytecode, "truncated at %d", i)
+	}
+
+	require.ErrorIs(t, (&Bytecode{}).UnmarshalBinary(append(data, 0)), ErrInvalidBytecode)
+
+	// a constant index past the end of the pool
+	bad := &Bytecode{code: []byte{byte(bcConst), 0, 9}, maxStack: 1, constants: []Value{1.0}}
+	data, err = bad.MarshalBinary()
+	require.NoError(t, err)
+	require.ErrorIs(t, (&Bytecode{}).UnmarshalBinary(data), ErrInvalidBytecode)
+
+	// an add with only one operand on the stack
+	bad = &Bytecode{code: []byte{byte(bcConst), 0, 0, byte(bcAdd)}, maxStack: 1, constants: []Value{1.0}}
+	data, err = bad.MarshalBinary()
+	require.NoError(t, err)
+	require.ErrorIs(t, (&Bytecode{}).UnmarshalBinary(data), ErrInvalidBytecode)
+}
+
+func BenchmarkVMRunFloat(b *testing.B) {
+	bc := compileBytecodeString(b, "(price * qty - discount) * (1 + tax) - limit")
+	vm := NewVM(bc)
+	scope := benchmarkScope()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := vm.RunFloat(scope); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVMRun(b *testing.B) {
+	vm := NewVM(compileBytecodeString(b, benchmarkExpr))
+	scope := benchmarkScope()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := vm.Run(scope); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestVMAssignment(t *testing.T) {
+	bc := compileBytecodeString(t, "x = y * 2\nx + 1")
+
+	data, err := bc.MarshalBinary()
+	require.NoError(t, err)
+	loaded := &Bytecode{}
+	require.NoError(t, loaded.UnmarshalBinary(data))
+
+	scope := MapScope{"y": 4.0}
+	v, err := NewVM(loaded).Run(scope)
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, v)
+	assert.Equal(t, 8.0, scope["x"])
+
+	// without a scope the value only lives for the run
+	v, err = NewVM(compileBytecodeString(t, "x = 2\nx * x")).Run(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, v)
+}
+
+func TestVMConditional(t *testing.T) {
+	bc := compileBytecodeString(t, "x > 0 ? 1 / x : fail()")
+
+	data, err := bc.MarshalBinary()
+	require.NoError(t, err)
+	loaded := &Bytecode{}
+	require.NoError(t, loaded.UnmarshalBinary(data))
+
+	// the branch that is not taken is never called
+	fail := Function(func(args ...Value) (Value, error) {
+		return nil, ErrArgumentValue
+	})
+	v, err := NewVM(loaded).RunFloat(MapScope{"x": 4.0, "fail": fail})
+	require.NoError(t, err)
+	assert.Equal(t, 0.25, v)
+
+	_, err = NewVM(loaded).Run(MapScope{"x": -4.0, "fail": fail})
+	require.ErrorIs(t, err, ErrArgumentValue)
+
+	// jumps backwards, past the end and into an operand are rejected
+	for _, code := range [][]byte{
+		{byte(bcConst), 0, 0, byte(bcJump), 0, 0},
+		{byte(bcConst), 0, 0, byte(bcJumpIfFalse), 0, 9, byte(bcConst), 0, 0},
+		{byte(bcConst), 0, 0, byte(bcJumpIfFalse), 0, 8, byte(bcConst), 0, 0},
+		{byte(bcConst), 0, 0, byte(bcJumpIfFalse), 0, 9, byte(bcConst), 0, 0, byte(bcConst), 0, 0},
+	} {
+		bad := &Bytecode{code: code, maxStack: 2, constants: []Value{1.0}}
+		data, err := bad.MarshalBinary()
+		require.NoError(t, err)
+		require.ErrorIs(t, (&Bytecode{}).UnmarshalBinary(data), ErrInvalidBytecode, "%v", code)
+	}
+}
+
+func TestVMLogicalShortCircuit(t *testing.T) {
+	fail := Function(func(args ...Value) (Value, error) {
+		return nil, ErrArgumentValue
+	})
+	scope := MapScope{"fail": fail}
+
+	for expr, expected := range map[string]bool{
+		"false and fail()":     false,
+		"1 or fail()":          true,
+		"0 or 0 and fail()":    false,
+		"1 and (0 or 2) and 3": true,
+		"not 0 or fail() or 1": true,
+		"(0 and fail()) xor 1": true,
+	} {
+		v, err := NewVM(compileBytecodeString(t, expr)).Run(scope)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, v, expr)
+	}
+}